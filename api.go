@@ -0,0 +1,94 @@
+package zooz
+
+import "context"
+
+// API is a set of all API entity clients. Client implements this interface, so your code may depend on API
+// and use Mock (or any other implementation) in tests.
+type API interface {
+	Caller
+	Payment() PaymentAPI
+	Customer() CustomerAPI
+	PaymentMethod() PaymentMethodAPI
+	Authorization() AuthorizationAPI
+	Charge() ChargeAPI
+	Capture() CaptureAPI
+	Void() VoidAPI
+	Refund() RefundAPI
+	Redirection() RedirectionAPI
+}
+
+// PaymentAPI is a set of methods for work with Payment entity. PaymentClient implements this interface.
+type PaymentAPI interface {
+	New(ctx context.Context, idempotencyKey string, params *PaymentParams) (*Payment, error)
+	Get(ctx context.Context, id string, expands ...PaymentExpand) (*Payment, error)
+	Update(ctx context.Context, id string, params *PaymentParams) (*Payment, error)
+}
+
+// CustomerAPI is a set of methods for work with Customer entity. CustomerClient implements this interface.
+type CustomerAPI interface {
+	New(ctx context.Context, idempotencyKey string, params *CustomerParams) (*Customer, error)
+	Get(ctx context.Context, id string) (*Customer, error)
+	Update(ctx context.Context, id string, params *CustomerParams) (*Customer, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// PaymentMethodAPI is a set of methods for work with PaymentMethod entity. PaymentMethodClient implements this interface.
+type PaymentMethodAPI interface {
+	New(ctx context.Context, idempotencyKey string, customerID string, token string) (*PaymentMethod, error)
+	Get(ctx context.Context, customerID string, token string) (*PaymentMethod, error)
+	GetList(ctx context.Context, customerID string) ([]PaymentMethod, error)
+}
+
+// AuthorizationAPI is a set of methods for work with Authorization entity. AuthorizationClient implements this interface.
+type AuthorizationAPI interface {
+	New(ctx context.Context, idempotencyKey string, paymentID string, params *AuthorizationParams, clientInfo *ClientInfo) (*Authorization, error)
+	Get(ctx context.Context, paymentID string, authorizationID string) (*Authorization, error)
+	GetList(ctx context.Context, paymentID string) ([]Authorization, error)
+}
+
+// ChargeAPI is a set of methods for work with Charge entity. ChargeClient implements this interface.
+type ChargeAPI interface {
+	New(ctx context.Context, idempotencyKey string, paymentID string, params *ChargeParams, clientInfo *ClientInfo) (*Charge, error)
+	Get(ctx context.Context, paymentID string, chargeID string) (*Charge, error)
+	GetList(ctx context.Context, paymentID string) ([]Charge, error)
+}
+
+// CaptureAPI is a set of methods for work with Capture entity. CaptureClient implements this interface.
+type CaptureAPI interface {
+	New(ctx context.Context, idempotencyKey string, paymentID string, params *CaptureParams) (*Capture, error)
+	Get(ctx context.Context, paymentID string, captureID string) (*Capture, error)
+	GetList(ctx context.Context, paymentID string) ([]Capture, error)
+}
+
+// VoidAPI is a set of methods for work with Void entity. VoidClient implements this interface.
+type VoidAPI interface {
+	New(ctx context.Context, idempotencyKey string, paymentID string) (*Void, error)
+	Get(ctx context.Context, paymentID string, voidID string) (*Void, error)
+	GetList(ctx context.Context, paymentID string) ([]Void, error)
+}
+
+// RefundAPI is a set of methods for work with Refund entity. RefundClient implements this interface.
+type RefundAPI interface {
+	New(ctx context.Context, idempotencyKey string, paymentID string, params *RefundParams) (*Refund, error)
+	Get(ctx context.Context, paymentID string, refundID string) (*Refund, error)
+	GetList(ctx context.Context, paymentID string) ([]Refund, error)
+}
+
+// RedirectionAPI is a set of methods for work with Redirection entity. RedirectionClient implements this interface.
+type RedirectionAPI interface {
+	Get(ctx context.Context, paymentID string, redirectionID string) (*Redirection, error)
+	GetList(ctx context.Context, paymentID string) ([]Redirection, error)
+}
+
+var (
+	_ API              = (*Client)(nil)
+	_ PaymentAPI       = (*PaymentClient)(nil)
+	_ CustomerAPI      = (*CustomerClient)(nil)
+	_ PaymentMethodAPI = (*PaymentMethodClient)(nil)
+	_ AuthorizationAPI = (*AuthorizationClient)(nil)
+	_ ChargeAPI        = (*ChargeClient)(nil)
+	_ CaptureAPI       = (*CaptureClient)(nil)
+	_ VoidAPI          = (*VoidClient)(nil)
+	_ RefundAPI        = (*RefundClient)(nil)
+	_ RedirectionAPI   = (*RedirectionClient)(nil)
+)
@@ -14,10 +14,12 @@ type AuthorizationClient struct {
 
 // Authorization is a model of entity.
 type Authorization struct {
+	RawFields
+
 	ID                         string                  `json:"id"`
 	Result                     Result                  `json:"result"`
 	Amount                     int64                   `json:"amount"`
-	Created                    json.Number             `json:"created"`
+	Created                    Timestamp               `json:"created"`
 	ReconciliationID           string                  `json:"reconciliation_id"`
 	PaymentMethod              PaymentMethodHref       `json:"payment_method"`
 	ThreeDSecureAttributes     *ThreeDSecureAttributes `json:"three_d_secure_attributes"`
@@ -29,6 +31,15 @@ type Authorization struct {
 	Redirection                *Redirection            `json:"redirection"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (a *Authorization) UnmarshalJSON(data []byte) error {
+	type model Authorization
+	if err := json.Unmarshal(data, (*model)(a)); err != nil {
+		return err
+	}
+	return a.RawFields.decode(data, a)
+}
+
 // AuthorizationParams is a set of params for creating entity.
 type AuthorizationParams struct {
 	PaymentMethod          PaymentMethodDetails    `json:"payment_method"`
@@ -37,6 +48,7 @@ type AuthorizationParams struct {
 	ThreeDSecureAttributes *ThreeDSecureAttributes `json:"three_d_secure_attributes,omitempty"`
 	Installments           *Installments           `json:"installments,omitempty"`
 	ProviderSpecificData   map[string]interface{}  `json:"provider_specific_data,omitempty"`
+	StoredCredential       *StoredCredential       `json:"stored_credential,omitempty"`
 }
 
 // New creates new Authorization entity.
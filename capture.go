@@ -15,13 +15,23 @@ type CaptureClient struct {
 // Capture is a model of entity.
 type Capture struct {
 	CaptureParams
+	RawFields
 
 	ID           string       `json:"id"`
 	Result       Result       `json:"result"`
-	Created      json.Number  `json:"created"`
+	Created      Timestamp    `json:"created"`
 	ProviderData ProviderData `json:"provider_data"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *Capture) UnmarshalJSON(data []byte) error {
+	type model Capture
+	if err := json.Unmarshal(data, (*model)(c)); err != nil {
+		return err
+	}
+	return c.RawFields.decode(data, c)
+}
+
 // CaptureParams is a set of params for creating entity.
 type CaptureParams struct {
 	ReconciliationID string `json:"reconciliation_id,omitempty"`
@@ -14,10 +14,12 @@ type ChargeClient struct {
 
 // Charge is a model of entity.
 type Charge struct {
+	RawFields
+
 	ID                         string                  `json:"id"`
 	Result                     Result                  `json:"result"`
 	Amount                     int64                   `json:"amount"`
-	Created                    json.Number             `json:"created"`
+	Created                    Timestamp               `json:"created"`
 	ReconciliationID           string                  `json:"reconciliation_id"`
 	PaymentMethod              PaymentMethodHref       `json:"payment_method"`
 	ThreeDSecureAttributes     *ThreeDSecureAttributes `json:"three_d_secure_attributes"`
@@ -29,6 +31,15 @@ type Charge struct {
 	Redirection                *Redirection            `json:"redirection"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *Charge) UnmarshalJSON(data []byte) error {
+	type model Charge
+	if err := json.Unmarshal(data, (*model)(c)); err != nil {
+		return err
+	}
+	return c.RawFields.decode(data, c)
+}
+
 // ChargeParams is a set of params for creating entity.
 type ChargeParams struct {
 	PaymentMethod          PaymentMethodDetails    `json:"payment_method"`
@@ -37,6 +48,7 @@ type ChargeParams struct {
 	ThreeDSecureAttributes *ThreeDSecureAttributes `json:"three_d_secure_attributes,omitempty"`
 	Installments           *Installments           `json:"installments,omitempty"`
 	ProviderSpecificData   map[string]interface{}  `json:"provider_specific_data,omitempty"`
+	StoredCredential       *StoredCredential       `json:"stored_credential,omitempty"`
 }
 
 // New creates new Charge entity.
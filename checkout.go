@@ -0,0 +1,432 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CheckoutStep is a step of checkout workflow.
+type CheckoutStep string
+
+// List of checkout steps in order of execution. CheckoutStepVoid and CheckoutStepDeleteCustomer are compensation
+// steps, they are executed only on failure.
+const (
+	CheckoutStepCustomer       CheckoutStep = "customer"
+	CheckoutStepPaymentMethod  CheckoutStep = "payment_method"
+	CheckoutStepPayment        CheckoutStep = "payment"
+	CheckoutStepAuthorization  CheckoutStep = "authorization"
+	CheckoutStepCapture        CheckoutStep = "capture"
+	CheckoutStepVoid           CheckoutStep = "void"
+	CheckoutStepDeleteCustomer CheckoutStep = "delete_customer"
+)
+
+// CheckoutStatus is a type of checkout outcome status.
+type CheckoutStatus string
+
+// List of possible checkout outcome statuses.
+const (
+	// CheckoutStatusCompleted means payment is authorized and captured.
+	CheckoutStatusCompleted CheckoutStatus = "completed"
+	// CheckoutStatusAuthorized means payment is authorized and capture was not requested.
+	CheckoutStatusAuthorized CheckoutStatus = "authorized"
+	// CheckoutStatusRedirectRequired means customer must be sent to CheckoutOutcome.RedirectURL.
+	// Run checkout again with the same params when customer returns.
+	CheckoutStatusRedirectRequired CheckoutStatus = "redirect_required"
+	// CheckoutStatusPending means provider processes authorization or capture asynchronously.
+	// Run checkout again with the same params later.
+	CheckoutStatusPending CheckoutStatus = "pending"
+	// CheckoutStatusDeclined means authorization is declined.
+	CheckoutStatusDeclined CheckoutStatus = "declined"
+	// CheckoutStatusCompensated means some step failed and already performed steps were compensated.
+	CheckoutStatusCompensated CheckoutStatus = "compensated"
+	// CheckoutStatusFailed means some step failed and checkout can't be compensated automatically, e.g. request
+	// failed with network error and it is unknown whether it was performed. Run checkout again with the same params
+	// to resume it.
+	CheckoutStatusFailed CheckoutStatus = "failed"
+)
+
+// CheckoutParams is a set of params for checkout workflow.
+type CheckoutParams struct {
+	// ID identifies checkout (e.g. order ID). It is a base for idempotency keys of all steps and a key of the
+	// step log, so running checkout with the same ID again resumes it.
+	ID string
+	// CustomerID is ID of existing customer. If empty, customer is created with Customer params,
+	// if they are given, otherwise payment is created without customer.
+	CustomerID string
+	Customer   *CustomerParams
+	// Token is a payment method token. If customer is known, token is attached to the customer as payment method.
+	Token string
+
+	Payment PaymentParams
+	// Authorization params. If payment method type is empty, tokenized payment method with Token is used.
+	Authorization AuthorizationParams
+	ClientInfo    *ClientInfo
+	// Capture params. If nil, full amount is captured.
+	Capture *CaptureParams
+	// AuthorizeOnly disables capture step.
+	AuthorizeOnly bool
+}
+
+// CheckoutOutcome is a structured result of checkout workflow.
+type CheckoutOutcome struct {
+	Status             CheckoutStatus
+	CustomerID         string
+	PaymentMethodToken string
+	PaymentID          string
+	Authorization      *Authorization
+	Capture            *Capture
+	Void               *Void
+	RedirectURL        string
+	// FailedStep and Err are set for declined, compensated and failed checkouts.
+	FailedStep CheckoutStep
+	Err        error
+}
+
+// CheckoutRecord is a record of checkout step log.
+type CheckoutRecord struct {
+	Step     CheckoutStep
+	EntityID string
+	Status   ResultStatus
+}
+
+// CheckoutLog persists performed checkout steps, so checkout may be resumed after crash.
+type CheckoutLog interface {
+	// Load returns records of given checkout in order they were saved.
+	Load(ctx context.Context, checkoutID string) ([]CheckoutRecord, error)
+	// Save appends record to the log of given checkout.
+	Save(ctx context.Context, checkoutID string, record CheckoutRecord) error
+}
+
+// Checkout performs create customer -> attach payment method -> create payment -> authorize -> capture workflow
+// with deterministic idempotency keys per step, and compensates performed steps on failure.
+type Checkout struct {
+	API API
+	Log CheckoutLog
+}
+
+// NewCheckout creates checkout workflow. If log is nil, in-memory log is used.
+func NewCheckout(api API, log CheckoutLog) *Checkout {
+	if log == nil {
+		log = NewMemoryCheckoutLog()
+	}
+	return &Checkout{API: api, Log: log}
+}
+
+// CheckoutIdempotencyKey returns idempotency key of given checkout step.
+func CheckoutIdempotencyKey(checkoutID string, step CheckoutStep) string {
+	return fmt.Sprintf("%s/%s", checkoutID, step)
+}
+
+type checkoutRun struct {
+	*Checkout
+	params  *CheckoutParams
+	records map[CheckoutStep]CheckoutRecord
+	outcome *CheckoutOutcome
+}
+
+// Run performs checkout or resumes it from the step log. Returned error is nil for completed, authorized,
+// pending and redirect-required checkouts. Declined, compensated and failed checkouts return outcome.Err.
+func (c *Checkout) Run(ctx context.Context, params *CheckoutParams) (*CheckoutOutcome, error) {
+	if params.ID == "" {
+		return nil, errors.New("checkout ID is empty")
+	}
+	if installments := params.Authorization.Installments; installments != nil {
+		var v validation
+		v.nested("authorization.installments", installments.ValidateAmount(params.Payment.Amount))
+		if err := v.err(); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := c.Log.Load(ctx, params.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load checkout log")
+	}
+
+	run := &checkoutRun{
+		Checkout: c,
+		params:   params,
+		records:  map[CheckoutStep]CheckoutRecord{},
+		outcome:  &CheckoutOutcome{CustomerID: params.CustomerID, PaymentMethodToken: params.Token},
+	}
+	for _, record := range records {
+		run.records[record.Step] = record
+	}
+
+	run.run(ctx)
+
+	return run.outcome, run.outcome.Err
+}
+
+func (r *checkoutRun) run(ctx context.Context) {
+	if r.compensated() {
+		return
+	}
+	if !r.customer(ctx) || !r.paymentMethod(ctx) || !r.payment(ctx) || !r.authorize(ctx) {
+		return
+	}
+	if r.params.AuthorizeOnly {
+		r.outcome.Status = CheckoutStatusAuthorized
+		return
+	}
+	r.capture(ctx)
+}
+
+func (r *checkoutRun) customer(ctx context.Context) bool {
+	if r.outcome.CustomerID != "" {
+		return true
+	}
+	if record, ok := r.records[CheckoutStepCustomer]; ok {
+		r.outcome.CustomerID = record.EntityID
+		return true
+	}
+	if r.params.Customer == nil {
+		return true
+	}
+
+	customer, err := r.API.Customer().New(ctx, r.key(CheckoutStepCustomer), r.params.Customer)
+	if err != nil {
+		return r.fail(CheckoutStepCustomer, err)
+	}
+	r.outcome.CustomerID = customer.ID
+	return r.save(ctx, CheckoutStepCustomer, customer.ID, ResultStatusSucceed)
+}
+
+func (r *checkoutRun) paymentMethod(ctx context.Context) bool {
+	if r.params.Token == "" || r.outcome.CustomerID == "" {
+		return true
+	}
+	if _, ok := r.records[CheckoutStepPaymentMethod]; ok {
+		return true
+	}
+
+	paymentMethod, err := r.API.PaymentMethod().New(ctx, r.key(CheckoutStepPaymentMethod), r.outcome.CustomerID, r.params.Token)
+	if err != nil {
+		r.fail(CheckoutStepPaymentMethod, err)
+		r.deleteCustomer(ctx)
+		return false
+	}
+	r.outcome.PaymentMethodToken = paymentMethod.Token
+	return r.save(ctx, CheckoutStepPaymentMethod, paymentMethod.Token, ResultStatusSucceed)
+}
+
+func (r *checkoutRun) payment(ctx context.Context) bool {
+	if record, ok := r.records[CheckoutStepPayment]; ok {
+		r.outcome.PaymentID = record.EntityID
+		return true
+	}
+
+	params := r.params.Payment
+	if params.CustomerID == "" {
+		params.CustomerID = r.outcome.CustomerID
+	}
+
+	payment, err := r.API.Payment().New(ctx, r.key(CheckoutStepPayment), &params)
+	if err != nil {
+		r.fail(CheckoutStepPayment, err)
+		r.deleteCustomer(ctx)
+		return false
+	}
+	r.outcome.PaymentID = payment.ID
+	return r.save(ctx, CheckoutStepPayment, payment.ID, ResultStatusSucceed)
+}
+
+func (r *checkoutRun) authorize(ctx context.Context) bool {
+	var authorization *Authorization
+	var err error
+
+	if record, ok := r.records[CheckoutStepAuthorization]; ok {
+		authorization, err = r.API.Authorization().Get(ctx, r.outcome.PaymentID, record.EntityID)
+	} else {
+		params := r.params.Authorization
+		if params.PaymentMethod.Type == "" {
+			params.PaymentMethod = PaymentMethodDetails{Type: "tokenized", Token: r.outcome.PaymentMethodToken}
+		}
+		authorization, err = r.API.Authorization().New(ctx, r.key(CheckoutStepAuthorization), r.outcome.PaymentID, &params, r.params.ClientInfo)
+	}
+	if err != nil {
+		r.fail(CheckoutStepAuthorization, err)
+		if isDefiniteFailure(err) {
+			r.deleteCustomer(ctx)
+		}
+		return false
+	}
+	r.outcome.Authorization = authorization
+
+	if record, ok := r.records[CheckoutStepAuthorization]; !ok || record.Status != authorization.Result.Status {
+		if !r.save(ctx, CheckoutStepAuthorization, authorization.ID, authorization.Result.Status) {
+			return false
+		}
+	}
+
+	switch {
+	case authorization.Result.IsPending():
+		r.outcome.Status = CheckoutStatusPending
+		if authorization.Redirection != nil && authorization.Redirection.URL != "" {
+			r.outcome.Status = CheckoutStatusRedirectRequired
+			r.outcome.RedirectURL = authorization.Redirection.URL
+		}
+		return false
+	case !authorization.Result.IsApproved():
+		r.outcome.Status = CheckoutStatusDeclined
+		r.outcome.FailedStep = CheckoutStepAuthorization
+		r.outcome.Err = errors.Errorf("authorization %s is declined: %s", authorization.ID, authorization.Result.Category)
+		r.deleteCustomer(ctx)
+		r.outcome.Status = CheckoutStatusDeclined
+		return false
+	}
+	return true
+}
+
+// compensated reports whether checkout was already compensated by previous run.
+func (r *checkoutRun) compensated() bool {
+	for _, step := range []CheckoutStep{CheckoutStepVoid, CheckoutStepDeleteCustomer} {
+		if record, ok := r.records[step]; ok {
+			r.outcome.Status = CheckoutStatusCompensated
+			r.outcome.Err = errors.Errorf("checkout was compensated by step %s of %s", step, record.EntityID)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *checkoutRun) capture(ctx context.Context) {
+	var capture *Capture
+	var err error
+
+	if record, ok := r.records[CheckoutStepCapture]; ok {
+		capture, err = r.API.Capture().Get(ctx, r.outcome.PaymentID, record.EntityID)
+	} else {
+		params := r.params.Capture
+		if params == nil {
+			params = &CaptureParams{}
+		}
+		capture, err = r.API.Capture().New(ctx, r.key(CheckoutStepCapture), r.outcome.PaymentID, params)
+	}
+	if err != nil {
+		// Capture may have been performed if error is ambiguous, so authorization is voided only if API rejected it.
+		r.fail(CheckoutStepCapture, err)
+		if isDefiniteFailure(err) {
+			r.void(ctx)
+		}
+		return
+	}
+	if capture.Result.IsFailed() {
+		r.outcome.Capture = capture
+		r.fail(CheckoutStepCapture, errors.Errorf("capture %s failed: %s", capture.ID, capture.Result.Category))
+		r.void(ctx)
+		return
+	}
+	r.outcome.Capture = capture
+
+	if record, ok := r.records[CheckoutStepCapture]; !ok || record.Status != capture.Result.Status {
+		if !r.save(ctx, CheckoutStepCapture, capture.ID, capture.Result.Status) {
+			return
+		}
+	}
+
+	if capture.Result.IsPending() {
+		r.outcome.Status = CheckoutStatusPending
+		return
+	}
+	r.outcome.Status = CheckoutStatusCompleted
+}
+
+// void compensates authorization after capture failure.
+func (r *checkoutRun) void(ctx context.Context) {
+	void, err := r.API.Void().New(ctx, r.key(CheckoutStepVoid), r.outcome.PaymentID)
+	if err == nil && !void.Result.IsApproved() {
+		err = errors.Errorf("void %s failed: %s", void.ID, void.Result.Category)
+	}
+	if err != nil {
+		r.outcome.Err = errors.Wrapf(r.outcome.Err, "failed to void authorization: %s", err)
+		return
+	}
+	r.outcome.Void = void
+	if r.save(ctx, CheckoutStepVoid, void.ID, void.Result.Status) {
+		r.outcome.Status = CheckoutStatusCompensated
+	}
+}
+
+// deleteCustomer compensates customer creation.
+func (r *checkoutRun) deleteCustomer(ctx context.Context) {
+	record, ok := r.records[CheckoutStepCustomer]
+	if !ok {
+		return
+	}
+	if err := r.API.Customer().Delete(ctx, record.EntityID); err != nil {
+		r.outcome.Err = errors.Wrapf(r.outcome.Err, "failed to delete customer: %s", err)
+		return
+	}
+	if r.save(ctx, CheckoutStepDeleteCustomer, record.EntityID, ResultStatusSucceed) {
+		r.outcome.Status = CheckoutStatusCompensated
+	}
+}
+
+func (r *checkoutRun) save(ctx context.Context, step CheckoutStep, entityID string, status ResultStatus) bool {
+	record := CheckoutRecord{Step: step, EntityID: entityID, Status: status}
+	if err := r.Log.Save(ctx, r.params.ID, record); err != nil {
+		r.outcome.Status = CheckoutStatusFailed
+		r.outcome.FailedStep = step
+		r.outcome.Err = errors.Wrap(err, "failed to save checkout log")
+		return false
+	}
+	r.records[step] = record
+	return true
+}
+
+func (r *checkoutRun) fail(step CheckoutStep, err error) bool {
+	r.outcome.Status = CheckoutStatusFailed
+	r.outcome.FailedStep = step
+	r.outcome.Err = errors.Wrapf(err, "checkout step %s failed", step)
+	return false
+}
+
+// isDefiniteFailure reports whether API rejected request, so the operation was not performed. Network errors,
+// timeouts, conflicts and server errors are ambiguous: the operation may have been performed.
+func isDefiniteFailure(err error) bool {
+	apiErr, ok := errors.Cause(err).(*Error)
+	if !ok || apiErr.StatusCode < 400 || apiErr.StatusCode >= 500 {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+func (r *checkoutRun) key(step CheckoutStep) string {
+	return CheckoutIdempotencyKey(r.params.ID, step)
+}
+
+// MemoryCheckoutLog is in-memory implementation of CheckoutLog. It doesn't survive process restart and is
+// intended for tests and single-process usage.
+type MemoryCheckoutLog struct {
+	mu      sync.Mutex
+	records map[string][]CheckoutRecord
+}
+
+// NewMemoryCheckoutLog creates empty MemoryCheckoutLog.
+func NewMemoryCheckoutLog() *MemoryCheckoutLog {
+	return &MemoryCheckoutLog{records: map[string][]CheckoutRecord{}}
+}
+
+// Load implements CheckoutLog interface.
+func (l *MemoryCheckoutLog) Load(ctx context.Context, checkoutID string) ([]CheckoutRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]CheckoutRecord(nil), l.records[checkoutID]...), nil
+}
+
+// Save implements CheckoutLog interface.
+func (l *MemoryCheckoutLog) Save(ctx context.Context, checkoutID string, record CheckoutRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[checkoutID] = append(l.records[checkoutID], record)
+	return nil
+}
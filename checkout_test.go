@@ -0,0 +1,222 @@
+package zooz
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newCheckoutTestParams() *CheckoutParams {
+	return &CheckoutParams{
+		ID:       "order-1",
+		Customer: &CustomerParams{CustomerReference: "customer-1"},
+		Token:    "token",
+		Payment:  PaymentParams{Amount: 1000, Currency: "USD"},
+	}
+}
+
+func TestCheckout_Completed(t *testing.T) {
+	mock := NewMock()
+	mock.On("Customer.New", "order-1/customer", MockAnything).Return(&Customer{ID: "customer_id"}, nil).Once()
+	mock.On("PaymentMethod.New", "order-1/payment_method", "customer_id", "token").Return(&PaymentMethod{Token: "token"}, nil).Once()
+	mock.On("Payment.New", "order-1/payment", &PaymentParams{Amount: 1000, Currency: "USD", CustomerID: "customer_id"}).
+		Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New", "order-1/authorization", "payment_id", &AuthorizationParams{
+		PaymentMethod: PaymentMethodDetails{Type: "tokenized", Token: "token"},
+	}, (*ClientInfo)(nil)).Return(&Authorization{ID: "authorization_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	mock.On("Capture.New", "order-1/capture", "payment_id", &CaptureParams{}).
+		Return(&Capture{ID: "capture_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	outcome, err := NewCheckout(mock, nil).Run(context.Background(), newCheckoutTestParams())
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if outcome.Status != CheckoutStatusCompleted {
+		t.Errorf("Invalid status: %s", outcome.Status)
+	}
+	if outcome.PaymentID != "payment_id" || outcome.Authorization.ID != "authorization_id" || outcome.Capture.ID != "capture_id" {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCheckout_CaptureFailureIsCompensated(t *testing.T) {
+	mock := NewMock()
+	mock.On("Customer.New").Return(&Customer{ID: "customer_id"}, nil).Once()
+	mock.On("PaymentMethod.New").Return(&PaymentMethod{Token: "token"}, nil).Once()
+	mock.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New").Return(&Authorization{ID: "authorization_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	mock.On("Capture.New").Return(nil, &Error{StatusCode: http.StatusBadRequest}).Once()
+	mock.On("Void.New", "order-1/void", "payment_id").Return(&Void{ID: "void_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	log := NewMemoryCheckoutLog()
+	checkout := NewCheckout(mock, log)
+
+	outcome, err := checkout.Run(context.Background(), newCheckoutTestParams())
+	if err == nil {
+		t.Fatal("Error must not be nil")
+	}
+	if outcome.Status != CheckoutStatusCompensated || outcome.FailedStep != CheckoutStepCapture {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	if outcome.Void == nil || outcome.Void.ID != "void_id" {
+		t.Errorf("Void is not as expected: %+v", outcome.Void)
+	}
+	mock.AssertExpectations(t)
+
+	// Compensated checkout is not performed again.
+	outcome, err = checkout.Run(context.Background(), newCheckoutTestParams())
+	if err == nil || outcome.Status != CheckoutStatusCompensated {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	if len(mock.Calls()) != 6 {
+		t.Errorf("Compensated checkout must not make calls: %+v", mock.Calls())
+	}
+}
+
+func TestCheckout_CaptureNetworkErrorIsResumable(t *testing.T) {
+	mock := NewMock()
+	mock.On("Customer.New").Return(&Customer{ID: "customer_id"}, nil).Once()
+	mock.On("PaymentMethod.New").Return(&PaymentMethod{Token: "token"}, nil).Once()
+	mock.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New").Return(&Authorization{ID: "authorization_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	mock.On("Capture.New").Return(nil, errors.New("timeout")).Once()
+
+	checkout := NewCheckout(mock, nil)
+	outcome, err := checkout.Run(context.Background(), newCheckoutTestParams())
+	if err == nil {
+		t.Fatal("Error must not be nil")
+	}
+	if outcome.Status != CheckoutStatusFailed || outcome.FailedStep != CheckoutStepCapture || outcome.Void != nil {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+
+	// Capture is retried with the same idempotency key.
+	mock.On("Authorization.Get", "payment_id", "authorization_id").Return(&Authorization{ID: "authorization_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	mock.On("Capture.New", "order-1/capture", "payment_id", MockAnything).Return(&Capture{ID: "capture_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	outcome, err = checkout.Run(context.Background(), newCheckoutTestParams())
+	if err != nil || outcome.Status != CheckoutStatusCompleted {
+		t.Errorf("Outcome is not as expected: %+v, %v", outcome, err)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCheckout_PaymentMethodFailureDeletesCustomer(t *testing.T) {
+	mock := NewMock()
+	mock.On("Customer.New").Return(&Customer{ID: "customer_id"}, nil).Once()
+	mock.On("PaymentMethod.New").Return(nil, errors.New("invalid_token")).Once()
+	mock.On("Customer.Delete", "customer_id").Return(nil, nil).Once()
+
+	outcome, err := NewCheckout(mock, nil).Run(context.Background(), newCheckoutTestParams())
+	if err == nil {
+		t.Fatal("Error must not be nil")
+	}
+	if outcome.Status != CheckoutStatusCompensated || outcome.FailedStep != CheckoutStepPaymentMethod {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCheckout_Declined(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New").Return(&Authorization{
+		ID:     "authorization_id",
+		Result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined},
+	}, nil).Once()
+
+	params := newCheckoutTestParams()
+	params.Customer = nil
+
+	outcome, err := NewCheckout(mock, nil).Run(context.Background(), params)
+	if err == nil {
+		t.Fatal("Error must not be nil")
+	}
+	if outcome.Status != CheckoutStatusDeclined || outcome.FailedStep != CheckoutStepAuthorization {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCheckout_DeclinedDeletesCustomer(t *testing.T) {
+	mock := NewMock()
+	mock.On("Customer.New").Return(&Customer{ID: "customer_id"}, nil).Once()
+	mock.On("PaymentMethod.New").Return(&PaymentMethod{Token: "token"}, nil).Once()
+	mock.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New").Return(&Authorization{
+		ID:     "authorization_id",
+		Result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined},
+	}, nil).Once()
+	mock.On("Customer.Delete", "customer_id").Return(nil, nil).Once()
+
+	outcome, err := NewCheckout(mock, nil).Run(context.Background(), newCheckoutTestParams())
+	if err == nil {
+		t.Fatal("Error must not be nil")
+	}
+	if outcome.Status != CheckoutStatusDeclined || outcome.FailedStep != CheckoutStepAuthorization {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCheckout_InstallmentsMismatch(t *testing.T) {
+	mock := NewMock()
+
+	params := newCheckoutTestParams()
+	params.Authorization.Installments = &Installments{NumberOfInstallments: 3, FirstPaymentAmount: 400, RemainingPaymentsAmount: 400}
+
+	_, err := NewCheckout(mock, nil).Run(context.Background(), params)
+	if fields := validationFields(t, err); !equalFields(fields, []string{"authorization.installments.first_payment_amount"}) {
+		t.Errorf("Invalid fields are %v", fields)
+	}
+	if len(mock.Calls()) != 0 {
+		t.Errorf("API must not be called: %+v", mock.Calls())
+	}
+}
+
+func TestCheckout_RedirectAndResume(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+	mock.On("Authorization.New").Return(&Authorization{
+		ID:          "authorization_id",
+		Result:      Result{Status: ResultStatusPending},
+		Redirection: &Redirection{URL: "https://acs.example.com"},
+	}, nil).Once()
+
+	params := newCheckoutTestParams()
+	params.Customer = nil
+	params.CustomerID = "customer_id"
+
+	log := NewMemoryCheckoutLog()
+	// Payment method was attached before crash.
+	if err := log.Save(context.Background(), "order-1", CheckoutRecord{Step: CheckoutStepPaymentMethod, EntityID: "token"}); err != nil {
+		t.Fatal(err)
+	}
+	checkout := NewCheckout(mock, log)
+
+	outcome, err := checkout.Run(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if outcome.Status != CheckoutStatusRedirectRequired || outcome.RedirectURL != "https://acs.example.com" {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+
+	// Customer returned from redirection.
+	mock.On("Authorization.Get", "payment_id", "authorization_id").Return(&Authorization{
+		ID:     "authorization_id",
+		Result: Result{Status: ResultStatusSucceed},
+	}, nil).Once()
+	mock.On("Capture.New").Return(&Capture{ID: "capture_id", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	outcome, err = checkout.Run(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if outcome.Status != CheckoutStatusCompleted {
+		t.Errorf("Invalid status: %s", outcome.Status)
+	}
+	mock.AssertExpectations(t)
+}
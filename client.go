@@ -12,6 +12,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 
 	"github.com/pkg/errors"
 )
@@ -37,6 +38,9 @@ type Client struct {
 	appID      string
 	privateKey string
 	env        env
+
+	unknownFieldsHook UnknownFieldsHook
+	validate          bool
 }
 
 type env string
@@ -107,6 +111,12 @@ func OptEnv(env env) Option {
 func (c *Client) Call(ctx context.Context, method, path string, headers map[string]string, reqObj interface{}, respObj interface{}) (callErr error) {
 	var reqBody io.Reader
 
+	if c.validate {
+		if err := validateRequest(reqObj); err != nil {
+			return err
+		}
+	}
+
 	if reqObj != nil {
 		reqBodyBytes, err := json.Marshal(reqObj)
 		if err != nil {
@@ -129,10 +139,7 @@ func (c *Client) Call(ctx context.Context, method, path string, headers map[stri
 
 	// Set common client headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set(headerAPIVersion, apiVersion)
-	req.Header.Set(headerEnv, string(c.env))
-	req.Header.Set(headerAppID, c.appID)
-	req.Header.Set(headerPrivateKey, c.privateKey)
+	c.setCommonHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -170,52 +177,65 @@ func (c *Client) Call(ctx context.Context, method, path string, headers map[stri
 		if err := json.Unmarshal(respBody, respObj); err != nil {
 			return errors.Wrapf(err, "failed to unmarshal response body: %s", string(respBody))
 		}
+		if c.unknownFieldsHook != nil {
+			if fields, err := unknownJSONFields(respBody, reflect.TypeOf(respObj)); err == nil && len(fields) > 0 {
+				c.unknownFieldsHook(method, path, fields)
+			}
+		}
 	}
 
 	return nil
 }
 
+// setCommonHeaders sets API version, environment and authentication headers.
+func (c *Client) setCommonHeaders(req *http.Request) {
+	req.Header.Set(headerAPIVersion, apiVersion)
+	req.Header.Set(headerEnv, string(c.env))
+	req.Header.Set(headerAppID, c.appID)
+	req.Header.Set(headerPrivateKey, c.privateKey)
+}
+
 // Payment creates client for work with corresponding entity.
-func (c *Client) Payment() *PaymentClient {
+func (c *Client) Payment() PaymentAPI {
 	return &PaymentClient{Caller: c}
 }
 
 // Customer creates client for work with corresponding entity.
-func (c *Client) Customer() *CustomerClient {
+func (c *Client) Customer() CustomerAPI {
 	return &CustomerClient{Caller: c}
 }
 
 // PaymentMethod creates client for work with corresponding entity.
-func (c *Client) PaymentMethod() *PaymentMethodClient {
+func (c *Client) PaymentMethod() PaymentMethodAPI {
 	return &PaymentMethodClient{Caller: c}
 }
 
 // Authorization creates client for work with corresponding entity.
-func (c *Client) Authorization() *AuthorizationClient {
+func (c *Client) Authorization() AuthorizationAPI {
 	return &AuthorizationClient{Caller: c}
 }
 
 // Charge creates client for work with corresponding entity.
-func (c *Client) Charge() *ChargeClient {
+func (c *Client) Charge() ChargeAPI {
 	return &ChargeClient{Caller: c}
 }
 
 // Capture creates client for work with corresponding entity.
-func (c *Client) Capture() *CaptureClient {
+func (c *Client) Capture() CaptureAPI {
 	return &CaptureClient{Caller: c}
 }
 
 // Void creates client for work with corresponding entity.
-func (c *Client) Void() *VoidClient {
+func (c *Client) Void() VoidAPI {
 	return &VoidClient{Caller: c}
 }
 
 // Refund creates client for work with corresponding entity.
-func (c *Client) Refund() *RefundClient {
+func (c *Client) Refund() RefundAPI {
 	return &RefundClient{Caller: c}
 }
 
 // Redirection creates client for work with corresponding entity.
-func (c *Client) Redirection() *RedirectionClient {
+func (c *Client) Redirection() RedirectionAPI {
 	return &RedirectionClient{Caller: c}
 }
@@ -1,12 +1,6 @@
 package zooz
 
-// Result represents status and category of some methods response.
-type Result struct {
-	Status      string `json:"status"`
-	Category    string `json:"category"`
-	SubCategory string `json:"sub_category"`
-	Description string `json:"description"`
-}
+import "encoding/json"
 
 // ClientInfo represents optional request params for some methods.
 type ClientInfo struct {
@@ -33,11 +27,31 @@ type Address struct {
 type AdditionalDetails map[string]string
 
 // ThreeDSecureAttributes is a set of attributes for 3D-Secure.
+// Top-level fields describe 3-D Secure 1 authentication. For 3-D Secure 2 use External attributes of authentication
+// performed by merchant's own 3DS server, or Internal attributes to let provider run authentication.
 type ThreeDSecureAttributes struct {
-	Encoding string `json:"encoding"`
-	XID      string `json:"xid"`
-	CAVV     string `json:"cavv"`
-	EciFlag  string `json:"eci_flag"`
+	Encoding string `json:"encoding,omitempty"`
+	XID      string `json:"xid,omitempty"`
+	CAVV     string `json:"cavv,omitempty"`
+	EciFlag  string `json:"eci_flag,omitempty"`
+
+	External *ThreeDSecureExternal `json:"external,omitempty"`
+	Internal *ThreeDSecureInternal `json:"internal,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+// 3-D Secure 1 fields are always sent if neither External nor Internal attributes are set, as before 3-D Secure 2.
+func (a ThreeDSecureAttributes) MarshalJSON() ([]byte, error) {
+	if a.External != nil || a.Internal != nil {
+		type model ThreeDSecureAttributes
+		return json.Marshal(model(a))
+	}
+	return json.Marshal(struct {
+		Encoding string `json:"encoding"`
+		XID      string `json:"xid"`
+		CAVV     string `json:"cavv"`
+		EciFlag  string `json:"eci_flag"`
+	}{Encoding: a.Encoding, XID: a.XID, CAVV: a.CAVV, EciFlag: a.EciFlag})
 }
 
 // Installments is a set of options of installments.
@@ -54,9 +68,11 @@ type ProviderData struct {
 	Description           string             `json:"description"`
 	RawResponse           string             `json:"raw_response"`
 	AvsCode               string             `json:"avs_code"`
+	CvvVerificationCode   string             `json:"cvv_verification_code"`
 	AuthorizationCode     string             `json:"authorization_code"`
 	TransactionID         string             `json:"transaction_id"`
 	ExternalID            string             `json:"external_id"`
+	NetworkTransactionID  string             `json:"network_transaction_id"`
 	Documents             []ProviderDocument `json:"documents"`
 	AdditionalInformation map[string]string  `json:"additional_information"`
 }
@@ -69,6 +85,7 @@ type ProviderDocument struct {
 }
 
 // PaymentMethodDetails represents payment method details for POST requests.
+// Use NewPaymentMethodDetails to build it from typed payment method, e.g. NewApplePay or NewCashVoucher.
 type PaymentMethodDetails struct {
 	Type              string            `json:"type"`
 	Token             string            `json:"token,omitempty"`
@@ -76,6 +93,9 @@ type PaymentMethodDetails struct {
 	SourceType        string            `json:"source_type,omitempty"`
 	Vendor            string            `json:"vendor,omitempty"`
 	AdditionalDetails AdditionalDetails `json:"additional_details,omitempty"`
+	ApplePay          *ApplePay         `json:"apple_pay,omitempty"`
+	GooglePay         *GooglePay        `json:"google_pay,omitempty"`
+	NetworkToken      *NetworkToken     `json:"network_token,omitempty"`
 }
 
 // PaymentMethodHref wraps PaymentMethod with associated href.
@@ -0,0 +1,159 @@
+package zooz
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// contractFixtures maps API response fixtures from testdata to models they must be decoded into.
+var contractFixtures = []struct {
+	file  string
+	model func() interface{}
+}{
+	{"payment.json", func() interface{} { return &Payment{} }},
+	{"payment_initialized.json", func() interface{} { return &Payment{} }},
+	{"customer.json", func() interface{} { return &Customer{} }},
+	{"payment_method.json", func() interface{} { return &PaymentMethod{} }},
+	{"authorization.json", func() interface{} { return &Authorization{} }},
+	{"charge.json", func() interface{} { return &Charge{} }},
+	{"capture.json", func() interface{} { return &Capture{} }},
+	{"void.json", func() interface{} { return &Void{} }},
+	{"refund.json", func() interface{} { return &Refund{} }},
+	{"redirection.json", func() interface{} { return &Redirection{} }},
+	{"error.json", func() interface{} { return &APIError{} }},
+}
+
+func TestContract_Fixtures(t *testing.T) {
+	for _, fixture := range contractFixtures {
+		t.Run(fixture.file, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", fixture.file))
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %s", err)
+			}
+
+			raw, err := decodeJSONWithNumbers(data)
+			if err != nil {
+				t.Fatalf("Fixture is not valid JSON: %s", err)
+			}
+
+			model := fixture.model()
+			if err := json.Unmarshal(data, model); err != nil {
+				t.Fatalf("Failed to decode fixture into %T: %s", model, err)
+			}
+
+			// Fields of API response which are not described in Go structs.
+			missing, err := unknownJSONFields(data, reflect.TypeOf(model))
+			if err != nil {
+				t.Fatalf("Failed to check fields: %s", err)
+			}
+			if len(missing) > 0 {
+				t.Errorf("Fields present in JSON but missing in %T:\n  %s", model, strings.Join(missing, "\n  "))
+			}
+
+			// Values which are changed after decode -> encode round-trip.
+			encoded, err := json.Marshal(model)
+			if err != nil {
+				t.Fatalf("Failed to encode %T: %s", model, err)
+			}
+			roundTrip, err := decodeJSONWithNumbers(encoded)
+			if err != nil {
+				t.Fatalf("Encoded model is not valid JSON: %s", err)
+			}
+			if diff := diffJSON("", raw, roundTrip); len(diff) > 0 {
+				t.Errorf("Values changed after round-trip of %T:\n  %s", model, strings.Join(diff, "\n  "))
+			}
+		})
+	}
+}
+
+// diffJSON returns paths of values from expected which are changed or lost in actual.
+// Keys absent in expected are ignored, as well as zero values lost because of omitempty.
+func diffJSON(path string, expected, actual interface{}) []string {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{strings.TrimSuffix(path, ".")}
+		}
+		var diff []string
+		for _, key := range sortedKeys(expectedValue) {
+			actualItem, ok := actualValue[key]
+			if !ok {
+				if !isZeroJSON(expectedValue[key]) {
+					diff = append(diff, path+key)
+				}
+				continue
+			}
+			diff = append(diff, diffJSON(path+key+".", expectedValue[key], actualItem)...)
+		}
+		return diff
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(expectedValue) {
+			return []string{strings.TrimSuffix(path, ".")}
+		}
+		var diff []string
+		for i := range expectedValue {
+			diff = append(diff, diffJSON(strings.TrimSuffix(path, ".")+"[].", expectedValue[i], actualValue[i])...)
+		}
+		return diff
+	default:
+		if !equalJSONScalars(expected, actual) && !(isZeroJSON(expected) && isZeroJSON(actual)) {
+			return []string{strings.TrimSuffix(path, ".")}
+		}
+		return nil
+	}
+}
+
+// equalJSONScalars compares scalar values. Numbers given as strings (e.g. "created": "1514550000000") are
+// equal to the same numbers without quotes, because API uses both forms and json.Number accepts both.
+func equalJSONScalars(expected, actual interface{}) bool {
+	if expectedNumber, ok := expected.(json.Number); ok {
+		expected = expectedNumber.String()
+	}
+	if actualNumber, ok := actual.(json.Number); ok {
+		actual = actualNumber.String()
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+func isZeroJSON(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case json.Number:
+		return v == "0"
+	case bool:
+		return !v
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func decodeJSONWithNumbers(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,26 @@
+package zooz
+
+import "encoding/json"
+
+// Credit is a model of entity. Credits are returned as Payment related resources.
+// https://developers.paymentsos.com/docs/api#/reference/credits
+type Credit struct {
+	RawFields
+
+	ID               string            `json:"id"`
+	Result           Result            `json:"result"`
+	Amount           int64             `json:"amount"`
+	Created          Timestamp         `json:"created"`
+	ReconciliationID string            `json:"reconciliation_id"`
+	PaymentMethod    PaymentMethodHref `json:"payment_method"`
+	ProviderData     ProviderData      `json:"provider_data"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *Credit) UnmarshalJSON(data []byte) error {
+	type model Credit
+	if err := json.Unmarshal(data, (*model)(c)); err != nil {
+		return err
+	}
+	return c.RawFields.decode(data, c)
+}
@@ -15,13 +15,23 @@ type CustomerClient struct {
 // Customer is a model of entity.
 type Customer struct {
 	CustomerParams
+	RawFields
 
 	ID             string          `json:"id"`
-	Created        json.Number     `json:"created"`
-	Modified       json.Number     `json:"modified"`
+	Created        Timestamp       `json:"created"`
+	Modified       Timestamp       `json:"modified"`
 	PaymentMethods []PaymentMethod `json:"payment_methods"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *Customer) UnmarshalJSON(data []byte) error {
+	type model Customer
+	if err := json.Unmarshal(data, (*model)(c)); err != nil {
+		return err
+	}
+	return c.RawFields.decode(data, c)
+}
+
 // CustomerParams is a set of params for creating and updating entity.
 type CustomerParams struct {
 	CustomerReference string            `json:"customer_reference"`
@@ -0,0 +1,173 @@
+package zooz
+
+// DeclineReason is a provider independent reason of failed transaction.
+type DeclineReason string
+
+// List of decline reasons.
+const (
+	DeclineReasonNone                  DeclineReason = ""
+	DeclineReasonInsufficientFunds     DeclineReason = "insufficient_funds"
+	DeclineReasonLimitExceeded         DeclineReason = "limit_exceeded"
+	DeclineReasonIssuerUnavailable     DeclineReason = "issuer_unavailable"
+	DeclineReasonTryAgainLater         DeclineReason = "try_again_later"
+	DeclineReasonProcessingError       DeclineReason = "processing_error"
+	DeclineReasonDoNotHonor            DeclineReason = "do_not_honor"
+	DeclineReasonExpiredCard           DeclineReason = "expired_card"
+	DeclineReasonInvalidCardNumber     DeclineReason = "invalid_card_number"
+	DeclineReasonInvalidCvv            DeclineReason = "invalid_cvv"
+	DeclineReasonLostOrStolen          DeclineReason = "lost_or_stolen"
+	DeclineReasonRestrictedCard        DeclineReason = "restricted_card"
+	DeclineReasonFraudSuspected        DeclineReason = "fraud_suspected"
+	DeclineReasonTransactionNotAllowed DeclineReason = "transaction_not_allowed"
+	DeclineReasonAuthenticationFailed  DeclineReason = "authentication_failed"
+	DeclineReasonConfigurationError    DeclineReason = "configuration_error"
+	DeclineReasonGenericDecline        DeclineReason = "generic_decline"
+	DeclineReasonUnknown               DeclineReason = "unknown"
+)
+
+// DeclineReasonInfo describes decline reason.
+type DeclineReasonInfo struct {
+	Class DeclineClass
+	// Message is safe to show to customer, it does not reveal details of fraud checks.
+	Message string
+}
+
+// DeclineReasons describes every decline reason. Reasons absent in the map are hard declines with message of
+// DeclineReasonUnknown.
+var DeclineReasons = map[DeclineReason]DeclineReasonInfo{
+	DeclineReasonInsufficientFunds:     {Class: DeclineClassSoft, Message: "Your card has insufficient funds."},
+	DeclineReasonLimitExceeded:         {Class: DeclineClassSoft, Message: "Your card has exceeded its limit."},
+	DeclineReasonIssuerUnavailable:     {Class: DeclineClassSoft, Message: "Your bank is not available at the moment. Please try again later."},
+	DeclineReasonTryAgainLater:         {Class: DeclineClassSoft, Message: "Your payment could not be processed. Please try again later."},
+	DeclineReasonProcessingError:       {Class: DeclineClassSoft, Message: "An error occurred while processing your payment. Please try again later."},
+	DeclineReasonDoNotHonor:            {Class: DeclineClassSoft, Message: "Your card was declined. Please contact your bank or use another card."},
+	DeclineReasonExpiredCard:           {Class: DeclineClassHard, Message: "Your card has expired."},
+	DeclineReasonInvalidCardNumber:     {Class: DeclineClassHard, Message: "Your card number is incorrect."},
+	DeclineReasonInvalidCvv:            {Class: DeclineClassHard, Message: "Your card's security code is incorrect."},
+	DeclineReasonLostOrStolen:          {Class: DeclineClassHard, Message: "Your card was declined. Please use another card."},
+	DeclineReasonRestrictedCard:        {Class: DeclineClassHard, Message: "Your card does not support this type of purchase."},
+	DeclineReasonFraudSuspected:        {Class: DeclineClassHard, Message: "Your card was declined. Please use another card."},
+	DeclineReasonTransactionNotAllowed: {Class: DeclineClassHard, Message: "Your card does not support this type of purchase."},
+	DeclineReasonAuthenticationFailed:  {Class: DeclineClassHard, Message: "Your card could not be authenticated. Please try again or use another card."},
+	DeclineReasonConfigurationError:    {Class: DeclineClassHard, Message: "An error occurred while processing your payment."},
+	DeclineReasonGenericDecline:        {Class: DeclineClassHard, Message: "Your card was declined. Please use another card."},
+	DeclineReasonUnknown:               {Class: DeclineClassHard, Message: "Your payment could not be processed."},
+}
+
+// DeclineCodes maps ProviderData.ResponseCode to decline reasons by ProviderData.ProviderName. Codes of provider ""
+// are ISO 8583 response codes used by most acquirers, they are checked when provider has no own mapping of the code.
+// Mappings of other providers may be added, e.g. DeclineCodes["Provider"] = map[string]DeclineReason{...}.
+var DeclineCodes = map[string]map[string]DeclineReason{
+	"": {
+		"04": DeclineReasonLostOrStolen,          // Pick up card
+		"05": DeclineReasonDoNotHonor,            // Do not honor
+		"07": DeclineReasonLostOrStolen,          // Pick up card, special condition
+		"12": DeclineReasonGenericDecline,        // Invalid transaction
+		"14": DeclineReasonInvalidCardNumber,     // Invalid card number
+		"41": DeclineReasonLostOrStolen,          // Lost card
+		"43": DeclineReasonLostOrStolen,          // Stolen card
+		"51": DeclineReasonInsufficientFunds,     // Insufficient funds
+		"54": DeclineReasonExpiredCard,           // Expired card
+		"57": DeclineReasonTransactionNotAllowed, // Transaction not permitted to cardholder
+		"58": DeclineReasonTransactionNotAllowed, // Transaction not permitted to terminal
+		"59": DeclineReasonFraudSuspected,        // Suspected fraud
+		"61": DeclineReasonLimitExceeded,         // Exceeds withdrawal amount limit
+		"62": DeclineReasonRestrictedCard,        // Restricted card
+		"65": DeclineReasonLimitExceeded,         // Exceeds withdrawal frequency limit
+		"82": DeclineReasonInvalidCvv,            // Negative CVV result
+		"91": DeclineReasonIssuerUnavailable,     // Issuer unavailable
+		"96": DeclineReasonProcessingError,       // System malfunction
+	},
+}
+
+// DeclineSubCategories maps result sub-categories to decline reasons. They are used when response code is unknown.
+var DeclineSubCategories = map[ResultSubCategory]DeclineReason{
+	ResultSubCategoryInsufficientFunds:      DeclineReasonInsufficientFunds,
+	ResultSubCategoryExceedsWithdrawalLimit: DeclineReasonLimitExceeded,
+	ResultSubCategoryIssuerUnavailable:      DeclineReasonIssuerUnavailable,
+	ResultSubCategoryTryAgainLater:          DeclineReasonTryAgainLater,
+	ResultSubCategoryDoNotHonor:             DeclineReasonDoNotHonor,
+	ResultSubCategoryCardExpired:            DeclineReasonExpiredCard,
+	ResultSubCategoryInvalidCardNumber:      DeclineReasonInvalidCardNumber,
+	ResultSubCategoryInvalidCvv:             DeclineReasonInvalidCvv,
+	ResultSubCategoryLostOrStolen:           DeclineReasonLostOrStolen,
+	ResultSubCategoryRestrictedCard:         DeclineReasonRestrictedCard,
+	ResultSubCategoryFraudSuspected:         DeclineReasonFraudSuspected,
+	ResultSubCategoryTransactionNotAllowed:  DeclineReasonTransactionNotAllowed,
+}
+
+// DeclineCategories maps result categories to decline reasons. They are used when neither response code
+// nor sub-category is known.
+var DeclineCategories = map[ResultCategory]DeclineReason{
+	ResultCategoryProviderError:               DeclineReasonProcessingError,
+	ResultCategoryProviderNetworkError:        DeclineReasonProcessingError,
+	ResultCategoryProviderAuthenticationError: DeclineReasonConfigurationError,
+	ResultCategoryPaymentMethodDeclined:       DeclineReasonGenericDecline,
+	ResultCategoryRiskDeclined:                DeclineReasonFraudSuspected,
+	ResultCategoryThreeDSecureFailed:          DeclineReasonAuthenticationFailed,
+}
+
+// Decline is a normalized decline of transaction.
+type Decline struct {
+	Reason DeclineReason
+	Class  DeclineClass
+	// Message is safe to show to customer.
+	Message      string
+	ProviderName string
+	ResponseCode string
+}
+
+// IsRetryable reports whether transaction may succeed if retried later with the same payment method.
+func (d Decline) IsRetryable() bool {
+	return d.Class == DeclineClassSoft
+}
+
+// NormalizeDecline maps provider response code, result category and sub-category of transaction to decline reason.
+// Zero Decline is returned for transactions which are not failed.
+func NormalizeDecline(result Result, providerData ProviderData) Decline {
+	if !result.IsFailed() {
+		return Decline{}
+	}
+
+	reason := declineReason(result, providerData)
+	info, ok := DeclineReasons[reason]
+	if !ok {
+		info = DeclineReasons[DeclineReasonUnknown]
+		info.Class = DeclineClassHard
+	}
+	return Decline{
+		Reason:       reason,
+		Class:        info.Class,
+		Message:      info.Message,
+		ProviderName: providerData.ProviderName,
+		ResponseCode: providerData.ResponseCode,
+	}
+}
+
+func declineReason(result Result, providerData ProviderData) DeclineReason {
+	if providerData.ResponseCode != "" {
+		if reason, ok := DeclineCodes[providerData.ProviderName][providerData.ResponseCode]; ok {
+			return reason
+		}
+		if reason, ok := DeclineCodes[""][providerData.ResponseCode]; ok {
+			return reason
+		}
+	}
+	if reason, ok := DeclineSubCategories[result.SubCategory]; ok {
+		return reason
+	}
+	if reason, ok := DeclineCategories[result.Category]; ok {
+		return reason
+	}
+	return DeclineReasonUnknown
+}
+
+// Decline returns normalized decline of authorization.
+func (a *Authorization) Decline() Decline {
+	return NormalizeDecline(a.Result, a.ProviderData)
+}
+
+// Decline returns normalized decline of charge.
+func (c *Charge) Decline() Decline {
+	return NormalizeDecline(c.Result, c.ProviderData)
+}
@@ -0,0 +1,96 @@
+package zooz
+
+import (
+	"testing"
+)
+
+func TestNormalizeDecline(t *testing.T) {
+	DeclineCodes["TestProvider"] = map[string]DeclineReason{
+		"51":  DeclineReasonFraudSuspected,
+		"N7":  DeclineReasonInvalidCvv,
+		"999": DeclineReasonTryAgainLater,
+	}
+	defer delete(DeclineCodes, "TestProvider")
+
+	declined := Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined}
+
+	tests := []struct {
+		name         string
+		result       Result
+		providerName string
+		responseCode string
+		reason       DeclineReason
+		class        DeclineClass
+	}{
+		{name: "approved", result: Result{Status: ResultStatusSucceed}, responseCode: "00", reason: DeclineReasonNone, class: DeclineClassNone},
+		{name: "pending", result: Result{Status: ResultStatusPending}, reason: DeclineReasonNone, class: DeclineClassNone},
+		{name: "iso insufficient funds", result: declined, responseCode: "51", reason: DeclineReasonInsufficientFunds, class: DeclineClassSoft},
+		{name: "iso expired card", result: declined, responseCode: "54", reason: DeclineReasonExpiredCard, class: DeclineClassHard},
+		{name: "iso stolen card", result: declined, providerName: "Other", responseCode: "43", reason: DeclineReasonLostOrStolen, class: DeclineClassHard},
+		{name: "provider code", result: declined, providerName: "TestProvider", responseCode: "N7", reason: DeclineReasonInvalidCvv, class: DeclineClassHard},
+		{name: "provider code overrides iso", result: declined, providerName: "TestProvider", responseCode: "51", reason: DeclineReasonFraudSuspected, class: DeclineClassHard},
+		{name: "provider soft code", result: declined, providerName: "TestProvider", responseCode: "999", reason: DeclineReasonTryAgainLater, class: DeclineClassSoft},
+		{name: "iso fallback for provider", result: declined, providerName: "TestProvider", responseCode: "91", reason: DeclineReasonIssuerUnavailable, class: DeclineClassSoft},
+		{
+			name:         "sub-category",
+			result:       Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryCardExpired},
+			responseCode: "unknown",
+			reason:       DeclineReasonExpiredCard,
+			class:        DeclineClassHard,
+		},
+		{
+			name:   "soft sub-category",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryTryAgainLater},
+			reason: DeclineReasonTryAgainLater,
+			class:  DeclineClassSoft,
+		},
+		{name: "generic decline", result: declined, reason: DeclineReasonGenericDecline, class: DeclineClassHard},
+		{name: "network error", result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderNetworkError}, reason: DeclineReasonProcessingError, class: DeclineClassSoft},
+		{name: "provider authentication", result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderAuthenticationError}, reason: DeclineReasonConfigurationError, class: DeclineClassHard},
+		{name: "risk", result: Result{Status: ResultStatusFailed, Category: ResultCategoryRiskDeclined}, reason: DeclineReasonFraudSuspected, class: DeclineClassHard},
+		{name: "3ds", result: Result{Status: ResultStatusFailed, Category: ResultCategoryThreeDSecureFailed}, reason: DeclineReasonAuthenticationFailed, class: DeclineClassHard},
+		{name: "unknown category", result: Result{Status: ResultStatusFailed, Category: "new_category"}, reason: DeclineReasonUnknown, class: DeclineClassHard},
+	}
+
+	for _, tt := range tests {
+		decline := NormalizeDecline(tt.result, ProviderData{ProviderName: tt.providerName, ResponseCode: tt.responseCode})
+		if decline.Reason != tt.reason || decline.Class != tt.class {
+			t.Errorf("Decline of %s is %s/%s, expected %s/%s", tt.name, decline.Reason, decline.Class, tt.reason, tt.class)
+		}
+		if decline.IsRetryable() != (tt.class == DeclineClassSoft) {
+			t.Errorf("Retryability of %s is %t", tt.name, decline.IsRetryable())
+		}
+		if tt.reason != DeclineReasonNone && decline.Message == "" {
+			t.Errorf("Message of %s is empty", tt.name)
+		}
+		if class := ClassifyDecline(tt.result, ProviderData{ProviderName: tt.providerName, ResponseCode: tt.responseCode}); class != tt.class {
+			t.Errorf("Class of %s is %s, expected %s", tt.name, class, tt.class)
+		}
+	}
+}
+
+func TestDeclineReasons(t *testing.T) {
+	reasons := map[DeclineReason]bool{}
+	for _, codes := range DeclineCodes {
+		for _, reason := range codes {
+			reasons[reason] = true
+		}
+	}
+	for _, reason := range DeclineSubCategories {
+		reasons[reason] = true
+	}
+	for _, reason := range DeclineCategories {
+		reasons[reason] = true
+	}
+
+	for reason := range reasons {
+		info, ok := DeclineReasons[reason]
+		if !ok {
+			t.Errorf("Reason %s is not described", reason)
+			continue
+		}
+		if info.Message == "" || (info.Class != DeclineClassSoft && info.Class != DeclineClassHard) {
+			t.Errorf("Reason %s is described incorrectly: %+v", reason, info)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Document is a provider document being downloaded, e.g. boleto PDF or barcode image. Body must be closed.
+type Document struct {
+	Descriptor string
+	// ContentType is taken from response, or from ProviderDocument if response has no Content-Type header.
+	ContentType string
+	// ContentLength is -1 if unknown.
+	ContentLength int64
+	Body          io.ReadCloser
+}
+
+// OpenDocument starts download of provider document and returns it as a stream. Credentials are sent only if
+// document is served by API. JSON response is treated as an error, unless document content type is JSON.
+func (c *Client) OpenDocument(ctx context.Context, document ProviderDocument) (doc *Document, openErr error) {
+	resolved, external, err := resolveHref(document.Href)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", resolved.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create HTTP request")
+	}
+	req = req.WithContext(ctx)
+	if !external {
+		c.setCommonHeaders(req)
+	}
+	if document.ContentType != "" {
+		req.Header.Set("Accept", document.ContentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do request")
+	}
+	// Body is closed here only on error, otherwise it is closed by caller.
+	defer func() {
+		if openErr == nil {
+			return
+		}
+		if err := resp.Body.Close(); err != nil {
+			openErr = errors.Wrapf(openErr, "failed to close response body: %s", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read response body")
+		}
+		return nil, errors.Errorf("failed to get document %s: status %d: %s", document.Href, resp.StatusCode, string(body))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = document.ContentType
+	}
+	if isJSONContentType(contentType) && !isJSONContentType(document.ContentType) {
+		return nil, errors.Errorf("failed to get document %s: response is not a document, content type %s", document.Href, contentType)
+	}
+	return &Document{
+		Descriptor:    document.Descriptor,
+		ContentType:   contentType,
+		ContentLength: resp.ContentLength,
+		Body:          resp.Body,
+	}, nil
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// Extension returns file extension of document content type with leading dot, e.g. ".pdf", or empty string
+// if content type is unknown.
+func (d *Document) Extension() string {
+	return contentTypeExtension(d.ContentType)
+}
+
+func contentTypeExtension(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "application/pdf":
+		return ".pdf"
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "text/html":
+		return ".html"
+	case "text/plain":
+		return ".txt"
+	}
+	if extensions, err := mime.ExtensionsByType(mediaType); err == nil && len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
+
+// DocumentStore stores downloaded provider documents.
+type DocumentStore interface {
+	// Put stores document content under given key.
+	Put(ctx context.Context, key string, contentType string, content io.Reader) error
+}
+
+// DirDocumentStore stores documents as files in directory. File name is the key with extension of content type.
+type DirDocumentStore struct {
+	Dir string
+}
+
+// Put implements DocumentStore interface.
+func (s DirDocumentStore) Put(ctx context.Context, key string, contentType string, content io.Reader) (putErr error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create documents directory")
+	}
+
+	path := filepath.Join(s.Dir, documentFileName(key)+contentTypeExtension(contentType))
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create document file")
+	}
+	defer func() {
+		if err := file.Close(); err != nil && putErr == nil {
+			putErr = errors.Wrap(err, "failed to close document file")
+		}
+	}()
+
+	if _, err := io.Copy(file, content); err != nil {
+		return errors.Wrap(err, "failed to write document file")
+	}
+	return nil
+}
+
+var unsafeFileNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// documentFileName replaces path separators and other unsafe characters of key.
+func documentFileName(key string) string {
+	return strings.Trim(unsafeFileNameRegexp.ReplaceAllString(key, "_"), ".")
+}
+
+// DocumentKey returns storage key of document with given index from ProviderData.Documents of transaction,
+// e.g. "payment_id/charge_id/0-boleto".
+func DocumentKey(paymentID, transactionID string, index int, document ProviderDocument) string {
+	key := fmt.Sprintf("%s/%s/%d", paymentID, transactionID, index)
+	if descriptor := documentFileName(document.Descriptor); descriptor != "" {
+		key += "-" + descriptor
+	}
+	return key
+}
+
+// SaveDocuments downloads all documents of provider data and puts them to the store under DocumentKey keys.
+// Keys of stored documents are returned.
+func (c *Client) SaveDocuments(ctx context.Context, store DocumentStore, paymentID, transactionID string, providerData ProviderData) ([]string, error) {
+	keys := make([]string, 0, len(providerData.Documents))
+	for i, document := range providerData.Documents {
+		key := DocumentKey(paymentID, transactionID, i, document)
+		if err := c.saveDocument(ctx, store, key, document); err != nil {
+			return keys, errors.Wrapf(err, "failed to save document %s", key)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (c *Client) saveDocument(ctx context.Context, store DocumentStore, key string, document ProviderDocument) (saveErr error) {
+	doc, err := c.OpenDocument(ctx, document)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := doc.Body.Close(); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}()
+	return store.Put(ctx, key, doc.ContentType, doc.Body)
+}
+
+// PaymentInstructions are instructions for customer to complete cash or bank transfer payment, extracted from
+// ProviderData.AdditionalInformation. Providers name these fields differently, known names are listed in
+// PaymentInstructionKeys.
+type PaymentInstructions struct {
+	Reference      string
+	Barcode        string
+	BankName       string
+	AccountNumber  string
+	AccountHolder  string
+	ExpirationDate string
+	URL            string
+	// Other contains additional information fields which are not recognized as instructions.
+	Other map[string]string
+}
+
+// PaymentInstructionKeys lists known names of AdditionalInformation fields for every PaymentInstructions field,
+// in order of priority. It may be extended for new providers.
+var PaymentInstructionKeys = map[string][]string{
+	"reference":       {"reference", "payment_reference", "reference_number", "voucher_number", "boleto_number", "order_reference"},
+	"barcode":         {"barcode", "bar_code", "barcode_number", "digitable_line", "linha_digitavel"},
+	"bank_name":       {"bank_name", "bank"},
+	"account_number":  {"account_number", "clabe", "iban", "bank_account"},
+	"account_holder":  {"account_holder", "beneficiary", "beneficiary_name"},
+	"expiration_date": {"expiration_date", "expiration", "due_date", "expires_at"},
+	"url":             {"url", "voucher_url", "payment_url", "instructions_url"},
+}
+
+// PaymentInstructions extracts payment instructions from additional information of provider data.
+func (p ProviderData) PaymentInstructions() PaymentInstructions {
+	var instructions PaymentInstructions
+	used := map[string]bool{}
+	lookup := func(field string) string {
+		for _, key := range PaymentInstructionKeys[field] {
+			if value, ok := p.AdditionalInformation[key]; ok && value != "" {
+				used[key] = true
+				return value
+			}
+		}
+		return ""
+	}
+
+	instructions.Reference = lookup("reference")
+	instructions.Barcode = lookup("barcode")
+	instructions.BankName = lookup("bank_name")
+	instructions.AccountNumber = lookup("account_number")
+	instructions.AccountHolder = lookup("account_holder")
+	instructions.ExpirationDate = lookup("expiration_date")
+	instructions.URL = lookup("url")
+
+	for key, value := range p.AdditionalInformation {
+		if used[key] {
+			continue
+		}
+		if instructions.Other == nil {
+			instructions.Other = map[string]string{}
+		}
+		instructions.Other[key] = value
+	}
+	return instructions
+}
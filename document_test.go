@@ -0,0 +1,122 @@
+package zooz
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_OpenDocument(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get(headerPrivateKey) != "private_key" {
+				t.Error("Credentials must be sent to API")
+			}
+			if r.URL.Path == "/documents/json" {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"category":"api_request_error"}`)),
+				}, nil
+			}
+			if r.URL.Path == "/documents/missing" {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBufferString("not found")),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": []string{"image/png"}},
+				ContentLength: 4,
+				Body:          ioutil.NopCloser(bytes.NewBufferString("\x89PNG")),
+			}, nil
+		},
+	}
+
+	client := New(OptHTTPClient(httpClientMock), OptPrivateKey("private_key"))
+
+	doc, err := client.OpenDocument(context.Background(), ProviderDocument{
+		Descriptor:  "barcode",
+		ContentType: "application/octet-stream",
+		Href:        "https://api.paymentsos.com/documents/barcode",
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	defer doc.Body.Close()
+
+	if doc.Descriptor != "barcode" || doc.ContentType != "image/png" || doc.ContentLength != 4 || doc.Extension() != ".png" {
+		t.Errorf("Document is not as expected: %+v", doc)
+	}
+
+	if _, err := client.OpenDocument(context.Background(), ProviderDocument{Href: "https://api.paymentsos.com/documents/missing"}); err == nil {
+		t.Error("Error expected for not found document")
+	}
+	if _, err := client.OpenDocument(context.Background(), ProviderDocument{Href: "https://api.paymentsos.com/documents/json"}); err == nil {
+		t.Error("Error expected for JSON response")
+	}
+}
+
+func TestClient_SaveDocuments(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("%PDF-1.4")),
+			}, nil
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "zooz-documents")
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := New(OptHTTPClient(httpClientMock))
+	providerData := ProviderData{Documents: []ProviderDocument{
+		{Descriptor: "boleto", ContentType: "application/pdf", Href: "https://provider.example.com/boleto.pdf"},
+	}}
+
+	keys, err := client.SaveDocuments(context.Background(), DirDocumentStore{Dir: dir}, "payment", "charge", providerData)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if len(keys) != 1 || keys[0] != "payment/charge/0-boleto" {
+		t.Errorf("Keys are not as expected: %v", keys)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "payment_charge_0-boleto.pdf"))
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if string(content) != "%PDF-1.4" {
+		t.Errorf("Invalid content: %s", content)
+	}
+}
+
+func TestProviderData_PaymentInstructions(t *testing.T) {
+	providerData := ProviderData{AdditionalInformation: map[string]string{
+		"voucher_number":  "123456",
+		"digitable_line":  "23790.50400 41990.901234",
+		"clabe":           "646180157000000004",
+		"due_date":        "2026-10-25",
+		"voucher_url":     "https://provider.example.com/voucher",
+		"store_locations": "https://provider.example.com/stores",
+	}}
+
+	instructions := providerData.PaymentInstructions()
+	if instructions.Reference != "123456" || instructions.Barcode != "23790.50400 41990.901234" ||
+		instructions.AccountNumber != "646180157000000004" || instructions.ExpirationDate != "2026-10-25" ||
+		instructions.URL != "https://provider.example.com/voucher" {
+		t.Errorf("Instructions are not as expected: %+v", instructions)
+	}
+	if len(instructions.Other) != 1 || instructions.Other["store_locations"] == "" {
+		t.Errorf("Other fields are not as expected: %v", instructions.Other)
+	}
+}
@@ -0,0 +1,232 @@
+package zooz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DeclineClass is a class of failed transaction from the point of view of retries.
+type DeclineClass string
+
+// List of possible decline classes.
+const (
+	// DeclineClassNone means transaction is not declined.
+	DeclineClassNone DeclineClass = ""
+	// DeclineClassSoft means retry with the same payment method may succeed later.
+	DeclineClassSoft DeclineClass = "soft"
+	// DeclineClassHard means retry with the same payment method is pointless.
+	DeclineClassHard DeclineClass = "hard"
+)
+
+// ClassifyDecline returns decline class of transaction by its result and provider response code.
+// See NormalizeDecline for details.
+func ClassifyDecline(result Result, providerData ProviderData) DeclineClass {
+	return NormalizeDecline(result, providerData).Class
+}
+
+// DunningPolicy is a calendar of retries after soft decline.
+type DunningPolicy struct {
+	// RetryDays are days after the first decline to retry at, e.g. [1, 3, 7]. Number of days is the maximal number
+	// of retries.
+	RetryDays []int
+	// RetryAt is a time of day to retry at, e.g. 10 hours. Zero means the same time of day as the first decline.
+	RetryAt time.Duration
+	// Location of RetryAt. Nil means UTC.
+	Location *time.Location
+	// SkipWeekends moves retries from Saturday and Sunday to Monday.
+	SkipWeekends bool
+}
+
+// DefaultDunningPolicy returns policy which retries 1, 3, 5 and 7 days after decline at 10:00 UTC on working days.
+func DefaultDunningPolicy() DunningPolicy {
+	return DunningPolicy{
+		RetryDays:    []int{1, 3, 5, 7},
+		RetryAt:      10 * time.Hour,
+		SkipWeekends: true,
+	}
+}
+
+// NextRetry returns time of retry with given index (starting with 0), or false if retries are exhausted.
+func (p DunningPolicy) NextRetry(firstDecline time.Time, retry int) (time.Time, bool) {
+	if retry < 0 || retry >= len(p.RetryDays) {
+		return time.Time{}, false
+	}
+
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
+	t := firstDecline.In(location).AddDate(0, 0, p.RetryDays[retry])
+	if p.RetryAt > 0 {
+		year, month, day := t.Date()
+		t = time.Date(year, month, day, 0, 0, 0, 0, location).Add(p.RetryAt)
+	}
+	if p.SkipWeekends {
+		for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	return t, true
+}
+
+// DunningStatus is a type of dunning case status.
+type DunningStatus string
+
+// List of possible dunning case statuses.
+const (
+	DunningStatusRetrying  DunningStatus = "retrying"
+	DunningStatusRecovered DunningStatus = "recovered"
+	// DunningStatusExhausted means all retries of the policy were declined.
+	DunningStatusExhausted DunningStatus = "exhausted"
+	// DunningStatusStopped means retries were stopped because of hard decline.
+	DunningStatusStopped DunningStatus = "stopped"
+)
+
+// DunningCase is a state of retries of one declined obligation, e.g. one subscription billing cycle.
+type DunningCase struct {
+	ID             string
+	Status         DunningStatus
+	Attempts       int
+	FirstDeclineAt time.Time
+	NextRetryAt    time.Time
+	LastResult     Result
+	// LastResponseCode is provider response code of the last declined attempt.
+	LastResponseCode string
+	LastClass        DeclineClass
+}
+
+// DunningStore persists dunning cases.
+type DunningStore interface {
+	// Get returns dunning case by ID or nil if it doesn't exist.
+	Get(ctx context.Context, id string) (*DunningCase, error)
+	// Save creates or updates dunning case.
+	Save(ctx context.Context, dunningCase DunningCase) error
+}
+
+// DunningEventType is a type of dunning lifecycle event.
+type DunningEventType string
+
+// List of possible dunning event types.
+const (
+	DunningEventRetryScheduled DunningEventType = "retry_scheduled"
+	DunningEventRecovered      DunningEventType = "recovered"
+	DunningEventExhausted      DunningEventType = "exhausted"
+	DunningEventStopped        DunningEventType = "stopped"
+)
+
+// DunningEvent is emitted by dunning engine on every change of dunning case.
+type DunningEvent struct {
+	Type DunningEventType
+	Case DunningCase
+}
+
+// DunningEngine tracks declined obligations and schedules retries of soft declines according to the policy.
+type DunningEngine struct {
+	Policy DunningPolicy
+	Store  DunningStore
+	// Classify classifies declines. If nil, ClassifyDecline is used.
+	Classify func(result Result, providerData ProviderData) DeclineClass
+	// OnEvent is called after dunning case is saved.
+	OnEvent func(event DunningEvent)
+}
+
+// NewDunningEngine creates dunning engine. If store is nil, in-memory store is used.
+func NewDunningEngine(policy DunningPolicy, store DunningStore, onEvent func(event DunningEvent)) *DunningEngine {
+	if store == nil {
+		store = NewMemoryDunningStore()
+	}
+	return &DunningEngine{Policy: policy, Store: store, OnEvent: onEvent}
+}
+
+// Handle updates dunning case with given ID by result of transaction made at given time and returns updated case.
+// The first declined transaction opens the case. Nil case is returned for approved and pending transactions
+// without open case.
+func (e *DunningEngine) Handle(ctx context.Context, id string, result Result, providerData ProviderData, at time.Time) (*DunningCase, error) {
+	dunningCase, err := e.Store.Get(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get dunning case %s", id)
+	}
+	if result.IsPending() || (dunningCase != nil && dunningCase.Status != DunningStatusRetrying) {
+		return dunningCase, nil
+	}
+
+	var event DunningEventType
+	if result.IsApproved() {
+		if dunningCase == nil {
+			return nil, nil
+		}
+		dunningCase.Attempts++
+		dunningCase.Status = DunningStatusRecovered
+		dunningCase.NextRetryAt = time.Time{}
+		event = DunningEventRecovered
+	} else {
+		if dunningCase == nil {
+			dunningCase = &DunningCase{ID: id, FirstDeclineAt: at}
+		}
+		classify := e.Classify
+		if classify == nil {
+			classify = ClassifyDecline
+		}
+		dunningCase.Attempts++
+		dunningCase.LastResult = result
+		dunningCase.LastResponseCode = providerData.ResponseCode
+		dunningCase.LastClass = classify(result, providerData)
+		dunningCase.NextRetryAt = time.Time{}
+
+		if dunningCase.LastClass == DeclineClassSoft {
+			if next, ok := e.Policy.NextRetry(dunningCase.FirstDeclineAt, dunningCase.Attempts-1); ok {
+				dunningCase.Status = DunningStatusRetrying
+				dunningCase.NextRetryAt = next
+				event = DunningEventRetryScheduled
+			} else {
+				dunningCase.Status = DunningStatusExhausted
+				event = DunningEventExhausted
+			}
+		} else {
+			dunningCase.Status = DunningStatusStopped
+			event = DunningEventStopped
+		}
+	}
+
+	if err := e.Store.Save(ctx, *dunningCase); err != nil {
+		return nil, errors.Wrapf(err, "failed to save dunning case %s", id)
+	}
+	if e.OnEvent != nil {
+		e.OnEvent(DunningEvent{Type: event, Case: *dunningCase})
+	}
+	return dunningCase, nil
+}
+
+// MemoryDunningStore is in-memory implementation of DunningStore. It doesn't survive process restart and is
+// intended for tests and single-process usage.
+type MemoryDunningStore struct {
+	mu    sync.Mutex
+	cases map[string]DunningCase
+}
+
+// NewMemoryDunningStore creates empty MemoryDunningStore.
+func NewMemoryDunningStore() *MemoryDunningStore {
+	return &MemoryDunningStore{cases: map[string]DunningCase{}}
+}
+
+// Get implements DunningStore interface.
+func (s *MemoryDunningStore) Get(ctx context.Context, id string) (*DunningCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dunningCase, ok := s.cases[id]
+	if !ok {
+		return nil, nil
+	}
+	return &dunningCase, nil
+}
+
+// Save implements DunningStore interface.
+func (s *MemoryDunningStore) Save(ctx context.Context, dunningCase DunningCase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cases[dunningCase.ID] = dunningCase
+	return nil
+}
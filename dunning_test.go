@@ -0,0 +1,154 @@
+package zooz
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClassifyDecline(t *testing.T) {
+	tests := []struct {
+		result       Result
+		responseCode string
+		expected     DeclineClass
+	}{
+		{result: Result{Status: ResultStatusSucceed}, expected: DeclineClassNone},
+		{result: Result{Status: ResultStatusPending}, expected: DeclineClassNone},
+		{result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined}, responseCode: "51", expected: DeclineClassSoft},
+		{result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined}, responseCode: "54", expected: DeclineClassHard},
+		{result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderNetworkError}, expected: DeclineClassSoft},
+		{result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryIssuerUnavailable}, expected: DeclineClassSoft},
+		{result: Result{Status: ResultStatusFailed, Category: ResultCategoryRiskDeclined}, expected: DeclineClassHard},
+	}
+
+	for _, tt := range tests {
+		if class := ClassifyDecline(tt.result, ProviderData{ResponseCode: tt.responseCode}); class != tt.expected {
+			t.Errorf("Class of %+v with code %q is %q, expected %q", tt.result, tt.responseCode, class, tt.expected)
+		}
+	}
+}
+
+func TestDunningPolicy_NextRetry(t *testing.T) {
+	p := DefaultDunningPolicy()
+	// Thursday
+	declined := time.Date(2018, time.January, 4, 17, 30, 0, 0, time.UTC)
+
+	expected := []time.Time{
+		time.Date(2018, time.January, 5, 10, 0, 0, 0, time.UTC),  // Friday
+		time.Date(2018, time.January, 8, 10, 0, 0, 0, time.UTC),  // Sunday moved to Monday
+		time.Date(2018, time.January, 9, 10, 0, 0, 0, time.UTC),  // Tuesday
+		time.Date(2018, time.January, 11, 10, 0, 0, 0, time.UTC), // Thursday
+	}
+	for i, e := range expected {
+		if next, ok := p.NextRetry(declined, i); !ok || !next.Equal(e) {
+			t.Errorf("Retry %d is at %s, expected %s", i, next, e)
+		}
+	}
+	if _, ok := p.NextRetry(declined, len(expected)); ok {
+		t.Errorf("Retries must be exhausted")
+	}
+
+	sameTime := DunningPolicy{RetryDays: []int{2}}
+	if next, _ := sameTime.NextRetry(declined, 0); !next.Equal(declined.AddDate(0, 0, 2)) {
+		t.Errorf("Retry at the same time of day is %s", next)
+	}
+}
+
+func TestDunningEngine_Handle(t *testing.T) {
+	ctx := context.Background()
+	declined := time.Date(2018, time.January, 4, 17, 30, 0, 0, time.UTC)
+	soft := Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryInsufficientFunds}
+
+	var events []DunningEventType
+	e := NewDunningEngine(DunningPolicy{RetryDays: []int{1, 2}}, nil, func(event DunningEvent) {
+		events = append(events, event.Type)
+	})
+
+	if c, err := e.Handle(ctx, "case", Result{Status: ResultStatusSucceed}, ProviderData{}, declined); c != nil || err != nil {
+		t.Fatalf("Approved transaction must not open case: %+v, %v", c, err)
+	}
+
+	c, err := e.Handle(ctx, "case", soft, ProviderData{ResponseCode: "51"}, declined)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if c.Status != DunningStatusRetrying || c.Attempts != 1 || !c.NextRetryAt.Equal(declined.AddDate(0, 0, 1)) || c.LastResponseCode != "51" {
+		t.Errorf("Case after first decline is not as expected: %+v", c)
+	}
+
+	c, _ = e.Handle(ctx, "case", soft, ProviderData{}, declined.AddDate(0, 0, 1))
+	if c.Status != DunningStatusRetrying || !c.NextRetryAt.Equal(declined.AddDate(0, 0, 2)) {
+		t.Errorf("Case after second decline is not as expected: %+v", c)
+	}
+
+	c, _ = e.Handle(ctx, "case", soft, ProviderData{}, declined.AddDate(0, 0, 2))
+	if c.Status != DunningStatusExhausted || c.Attempts != 3 {
+		t.Errorf("Case after third decline is not as expected: %+v", c)
+	}
+
+	c, _ = e.Handle(ctx, "hard", Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryCardExpired}, ProviderData{}, declined)
+	if c.Status != DunningStatusStopped || c.LastClass != DeclineClassHard {
+		t.Errorf("Case after hard decline is not as expected: %+v", c)
+	}
+
+	e.Handle(ctx, "recovered", soft, ProviderData{}, declined)
+	c, _ = e.Handle(ctx, "recovered", Result{Status: ResultStatusSucceed}, ProviderData{}, declined.AddDate(0, 0, 1))
+	if c.Status != DunningStatusRecovered || c.Attempts != 2 {
+		t.Errorf("Case after recovery is not as expected: %+v", c)
+	}
+
+	expected := []DunningEventType{
+		DunningEventRetryScheduled, DunningEventRetryScheduled, DunningEventExhausted,
+		DunningEventStopped,
+		DunningEventRetryScheduled, DunningEventRecovered,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Events are %v, expected %v", events, expected)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Events are %v, expected %v", events, expected)
+			break
+		}
+	}
+}
+
+func TestSubscriptionScheduler_Dunning(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2018, time.January, 1, 9, 0, 0, 0, time.UTC)
+	soft := &Charge{Result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined}, ProviderData: ProviderData{ResponseCode: "51"}}
+	hard := &Charge{Result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined}, ProviderData: ProviderData{ResponseCode: "43"}}
+
+	m := NewMock()
+	m.On("Payment.New", MockAnything, MockAnything).Return(&Payment{ID: "payment"}, nil)
+	m.On("Charge.New", "sub/0/charge/0", MockAnything, MockAnything, MockAnything).Return(soft, nil).Once()
+	m.On("Charge.New", "sub/0/charge/1", MockAnything, MockAnything, MockAnything).Return(hard, nil).Once()
+
+	store := NewMemorySubscriptionStore()
+	s := NewSubscriptionScheduler(m, store)
+	s.Dunning = NewDunningEngine(DunningPolicy{RetryDays: []int{2, 4}, RetryAt: 10 * time.Hour}, nil, nil)
+	if err := s.Subscribe(ctx, Subscription{ID: "sub", Token: "token", Amount: 100, Currency: "USD", IntervalMonths: 1, StartAt: start, NetworkTransactionID: "ntid"}); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	s.RunDue(ctx, start)
+	sub, _ := store.Get(ctx, "sub")
+	if sub.Status != SubscriptionStatusPastDue || !sub.NextBillingAt.Equal(time.Date(2018, time.January, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Subscription after soft decline is not as expected: %+v", sub)
+	}
+
+	if results, _ := s.RunDue(ctx, start.AddDate(0, 0, 1)); len(results) != 0 {
+		t.Errorf("Subscription must not be retried before scheduled time: %+v", results)
+	}
+
+	s.RunDue(ctx, sub.NextBillingAt)
+	sub, _ = store.Get(ctx, "sub")
+	if sub.Status != SubscriptionStatusUnpaid {
+		t.Errorf("Subscription after hard decline is not as expected: %+v", sub)
+	}
+	if results, _ := s.RunDue(ctx, start.AddDate(1, 0, 0)); len(results) != 0 {
+		t.Errorf("Unpaid subscription must not be billed: %+v", results)
+	}
+
+	m.AssertExpectations(t)
+}
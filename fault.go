@@ -0,0 +1,250 @@
+package zooz
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FaultKind is a type of fault injected by FaultHTTPClient.
+type FaultKind string
+
+// List of possible fault kinds.
+const (
+	// FaultLatency delays request by FaultRule.Latency and then performs it as usual.
+	FaultLatency FaultKind = "latency"
+	// FaultTimeout waits FaultRule.Latency (or until request context is done) and returns timeout error
+	// without performing request.
+	FaultTimeout FaultKind = "timeout"
+	// FaultConnectionReset returns "connection reset by peer" error without performing request.
+	FaultConnectionReset FaultKind = "connection_reset"
+	// FaultStatus returns response with FaultRule.StatusCode (e.g. 500, 503 or 429) without performing request.
+	FaultStatus FaultKind = "status"
+	// FaultTruncatedBody performs request and cuts response body at random position.
+	FaultTruncatedBody FaultKind = "truncated_body"
+	// FaultMalformedBody performs request and makes response body invalid JSON.
+	FaultMalformedBody FaultKind = "malformed_body"
+	// FaultDuplicateResponse performs request twice and returns the second response,
+	// as if request was retried by some proxy on the way.
+	FaultDuplicateResponse FaultKind = "duplicate_response"
+)
+
+// FaultRule describes which requests are affected and which fault is injected.
+type FaultRule struct {
+	// Method is HTTP method of affected requests. Empty value matches any method.
+	Method string
+	// Path is a pattern (in terms of path.Match) of API path without leading slash, e.g. "payments/*/captures".
+	// Empty value matches any path.
+	Path string
+	// Probability of fault in range (0, 1]. Zero value means fault is injected every time.
+	Probability float64
+	// Fault is a kind of injected fault.
+	Fault FaultKind
+	// Latency is used by FaultLatency and FaultTimeout.
+	Latency time.Duration
+	// StatusCode is used by FaultStatus.
+	StatusCode int
+	// Header is added to response by FaultStatus, e.g. "Retry-After" for 429 status.
+	Header http.Header
+	// Body is returned by FaultStatus. If empty, API error with "api_error" category is returned.
+	Body string
+}
+
+// FaultHTTPClient is HTTPClient wrapper which injects faults for resilience testing.
+// Rules are checked in given order, first matched rule which passes probability check is applied.
+// All random decisions are made with seeded source, so test runs are reproducible.
+type FaultHTTPClient struct {
+	httpClient HTTPClient
+	rules      []FaultRule
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+const faultDefaultBody = `{"category":"api_error","description":"Injected fault","more_info":""}`
+
+// NewFaultHTTPClient creates FaultHTTPClient which wraps given HTTP client.
+func NewFaultHTTPClient(httpClient HTTPClient, seed int64, rules ...FaultRule) *FaultHTTPClient {
+	return &FaultHTTPClient{
+		httpClient: httpClient,
+		rules:      rules,
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Do implements HTTPClient interface.
+func (c *FaultHTTPClient) Do(r *http.Request) (*http.Response, error) {
+	rule := c.match(r)
+	if rule == nil {
+		return c.httpClient.Do(r)
+	}
+
+	switch rule.Fault {
+	case FaultLatency:
+		if err := faultSleep(r.Context(), rule.Latency); err != nil {
+			return nil, err
+		}
+		return c.httpClient.Do(r)
+
+	case FaultTimeout:
+		if err := faultSleep(r.Context(), rule.Latency); err != nil {
+			return nil, err
+		}
+		return nil, &url.Error{Op: r.Method, URL: r.URL.String(), Err: faultTimeoutError{}}
+
+	case FaultConnectionReset:
+		return nil, &url.Error{
+			Op:  r.Method,
+			URL: r.URL.String(),
+			Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+		}
+
+	case FaultStatus:
+		body := rule.Body
+		if body == "" {
+			body = faultDefaultBody
+		}
+		header := http.Header{}
+		for key, values := range rule.Header {
+			header[key] = values
+		}
+		return &http.Response{
+			Status:     http.StatusText(rule.StatusCode),
+			StatusCode: rule.StatusCode,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+
+	case FaultTruncatedBody:
+		return c.rewriteBody(r, func(body []byte) []byte {
+			if len(body) == 0 {
+				return body
+			}
+			return body[:c.intn(len(body))]
+		})
+
+	case FaultMalformedBody:
+		return c.rewriteBody(r, func(body []byte) []byte {
+			return append(body, '}')
+		})
+
+	case FaultDuplicateResponse:
+		return c.duplicate(r)
+	}
+
+	return nil, errors.Errorf("unknown fault kind %q", rule.Fault)
+}
+
+func (c *FaultHTTPClient) match(r *http.Request) *FaultRule {
+	reqPath := strings.TrimPrefix(r.URL.Path, "/")
+	for i := range c.rules {
+		rule := &c.rules[i]
+		if rule.Method != "" && !strings.EqualFold(rule.Method, r.Method) {
+			continue
+		}
+		if rule.Path != "" {
+			if ok, _ := path.Match(rule.Path, reqPath); !ok {
+				continue
+			}
+		}
+		if rule.Probability > 0 && c.float64() >= rule.Probability {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+func (c *FaultHTTPClient) rewriteBody(r *http.Request, rewrite func([]byte) []byte) (*http.Response, error) {
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	body = rewrite(body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+func (c *FaultHTTPClient) duplicate(r *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if r.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		if err := r.Body.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	first := r.WithContext(r.Context())
+	first.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	resp, err := c.httpClient.Do(first)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ioutil.ReadAll(resp.Body); err != nil {
+		return nil, err
+	}
+	if err := resp.Body.Close(); err != nil {
+		return nil, err
+	}
+
+	second := r.WithContext(r.Context())
+	second.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	return c.httpClient.Do(second)
+}
+
+func (c *FaultHTTPClient) float64() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64()
+}
+
+func (c *FaultHTTPClient) intn(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Intn(n)
+}
+
+func faultSleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// faultTimeoutError implements net.Error as timeout.
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "injected fault: i/o timeout" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
@@ -0,0 +1,196 @@
+package zooz
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFaultTestUpstream(calls *int) *httpClientMock {
+	return &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			*calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"id":"payment_id","amount":100}`)),
+			}, nil
+		},
+	}
+}
+
+func newFaultTestRequest(t *testing.T, method, path string) *http.Request {
+	r, err := http.NewRequest(method, apiURL+path, bytes.NewBufferString(`{"amount":100}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %s", err)
+	}
+	return r
+}
+
+func TestFaultHTTPClient_NoMatch(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{
+		Method:     "POST",
+		Path:       "payments/*/captures",
+		Fault:      FaultStatus,
+		StatusCode: http.StatusInternalServerError,
+	})
+
+	resp, err := c.Do(newFaultTestRequest(t, "GET", "payments/id/captures"))
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Invalid status code: %d", resp.StatusCode)
+	}
+	if calls != 1 {
+		t.Errorf("Invalid upstream calls count: %d", calls)
+	}
+}
+
+func TestFaultHTTPClient_Status(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{
+		Path:       "payments/*/captures",
+		Fault:      FaultStatus,
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"1"}},
+	})
+
+	client := New(OptHTTPClient(c))
+	err := client.Call(context.Background(), "POST", "payments/id/captures", nil, nil, nil)
+
+	zoozErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Invalid error type: %T", err)
+	}
+	if zoozErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Invalid status code: %d", zoozErr.StatusCode)
+	}
+	if zoozErr.APIError.Category != "api_error" {
+		t.Errorf("Invalid API error category: %s", zoozErr.APIError.Category)
+	}
+	if calls != 0 {
+		t.Errorf("Invalid upstream calls count: %d", calls)
+	}
+}
+
+func TestFaultHTTPClient_ConnectionReset(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{Fault: FaultConnectionReset})
+
+	_, err := c.Do(newFaultTestRequest(t, "POST", "payments"))
+	if err == nil || !strings.Contains(err.Error(), "connection reset by peer") {
+		t.Errorf("Invalid error: %v", err)
+	}
+}
+
+func TestFaultHTTPClient_Timeout(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{Fault: FaultTimeout, Latency: time.Millisecond})
+
+	_, err := c.Do(newFaultTestRequest(t, "POST", "payments"))
+	netErr, ok := err.(net.Error)
+	if !ok || !netErr.Timeout() {
+		t.Errorf("Error must be timeout: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Invalid upstream calls count: %d", calls)
+	}
+}
+
+func TestFaultHTTPClient_LatencyRespectsContext(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{Fault: FaultLatency, Latency: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.Do(newFaultTestRequest(t, "POST", "payments").WithContext(ctx))
+	if err != context.Canceled {
+		t.Errorf("Invalid error: %v", err)
+	}
+}
+
+func TestFaultHTTPClient_MalformedBody(t *testing.T) {
+	var calls int
+	c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 1, FaultRule{Fault: FaultMalformedBody})
+
+	client := New(OptHTTPClient(c))
+	err := client.Call(context.Background(), "GET", "payments/id", nil, nil, &Payment{})
+	if err == nil || !strings.Contains(err.Error(), "failed to unmarshal response body") {
+		t.Errorf("Invalid error: %v", err)
+	}
+}
+
+func TestFaultHTTPClient_TruncatedBodyIsReproducible(t *testing.T) {
+	read := func() string {
+		var calls int
+		c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 42, FaultRule{Fault: FaultTruncatedBody})
+		resp, err := c.Do(newFaultTestRequest(t, "GET", "payments/id"))
+		if err != nil {
+			t.Fatalf("Error must be nil: %s", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	first, second := read(), read()
+	if first != second {
+		t.Errorf("Truncated bodies differ for the same seed: %q and %q", first, second)
+	}
+	if len(first) >= len(`{"id":"payment_id","amount":100}`) {
+		t.Errorf("Body is not truncated: %q", first)
+	}
+}
+
+func TestFaultHTTPClient_DuplicateResponse(t *testing.T) {
+	var bodies []string
+	upstream := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+			}, nil
+		},
+	}
+	c := NewFaultHTTPClient(upstream, 1, FaultRule{Method: "POST", Fault: FaultDuplicateResponse})
+
+	if _, err := c.Do(newFaultTestRequest(t, "POST", "payments")); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"amount":100}` || bodies[1] != `{"amount":100}` {
+		t.Errorf("Request must be sent twice with the same body: %q", bodies)
+	}
+}
+
+func TestFaultHTTPClient_Probability(t *testing.T) {
+	count := func() int {
+		var calls int
+		c := NewFaultHTTPClient(newFaultTestUpstream(&calls), 7, FaultRule{
+			Fault:       FaultStatus,
+			StatusCode:  http.StatusServiceUnavailable,
+			Probability: 0.5,
+		})
+		for i := 0; i < 100; i++ {
+			if _, err := c.Do(newFaultTestRequest(t, "GET", "payments/id")); err != nil {
+				t.Fatalf("Error must be nil: %s", err)
+			}
+		}
+		return calls
+	}
+
+	first := count()
+	if first == 0 || first == 100 {
+		t.Errorf("Faults must be injected partially: %d requests passed", first)
+	}
+	if second := count(); second != first {
+		t.Errorf("Results differ for the same seed: %d and %d", first, second)
+	}
+}
@@ -0,0 +1,180 @@
+package zooz
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// PaymentLedger is a view of payment balances calculated from its related resources.
+// Only successful transactions are counted in balances. Pending captures and refunds are tracked separately
+// and reserve the amount, so checks don't allow to capture or refund it twice.
+type PaymentLedger struct {
+	Authorized      int64
+	Charged         int64
+	Captured        int64
+	Refunded        int64
+	PendingCaptured int64
+	PendingRefunded int64
+	Voided          bool
+	// Captures are balances of successful captures in order they were made.
+	Captures []CaptureBalance
+	// UnassignedRefunded is a sum of successful refunds without capture ID.
+	UnassignedRefunded int64
+}
+
+// CaptureBalance is a balance of one capture.
+type CaptureBalance struct {
+	CaptureID       string
+	Captured        int64
+	Refunded        int64
+	PendingRefunded int64
+}
+
+// Refundable returns amount which may be refunded from the capture.
+func (b CaptureBalance) Refundable() int64 {
+	return nonNegative(b.Captured - b.Refunded - b.PendingRefunded)
+}
+
+// BalanceError is returned when requested amount exceeds available balance.
+type BalanceError struct {
+	Action    PaymentAction
+	Requested int64
+	Available int64
+}
+
+// Error implements error interface.
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("%s of %d exceeds available amount %d", e.Action, e.Requested, e.Available)
+}
+
+// NewPaymentLedger calculates ledger of payment. Payment must be requested with authorizations, charges, captures,
+// voids and refunds expansions (e.g. with PaymentExpandAll).
+func NewPaymentLedger(payment *Payment) (*PaymentLedger, error) {
+	resources := payment.RelatedResources
+	if resources == nil {
+		return nil, errors.Errorf("payment %s has no related resources, get it with expansions", payment.ID)
+	}
+
+	l := &PaymentLedger{}
+
+	for _, authorization := range resources.Authorizations {
+		if authorization.Result.IsApproved() {
+			l.Authorized += authorization.Amount
+		}
+	}
+	for _, charge := range resources.Charges {
+		if charge.Result.IsApproved() {
+			l.Charged += charge.Amount
+		}
+	}
+	for _, void := range resources.Voids {
+		if void.Result.IsApproved() {
+			l.Voided = true
+		}
+	}
+
+	captures := map[string]int{}
+	for _, capture := range resources.Captures {
+		amount := capture.Amount
+		if amount == 0 {
+			// Capture without amount captures the rest of authorized amount.
+			amount = nonNegative(l.Authorized - l.Captured - l.PendingCaptured)
+		}
+		switch {
+		case capture.Result.IsApproved():
+			l.Captured += amount
+			captures[capture.ID] = len(l.Captures)
+			l.Captures = append(l.Captures, CaptureBalance{CaptureID: capture.ID, Captured: amount})
+		case capture.Result.IsPending():
+			l.PendingCaptured += amount
+		}
+	}
+
+	for _, refund := range resources.Refunds {
+		if !refund.Result.IsApproved() && !refund.Result.IsPending() {
+			continue
+		}
+		amount := refund.Amount
+		index, hasCapture := captures[refund.CaptureID]
+		if amount == 0 {
+			// Refund without amount refunds the rest of captured amount.
+			if hasCapture {
+				amount = l.Captures[index].Refundable()
+			} else {
+				amount = l.Refundable()
+			}
+		}
+		if refund.Result.IsPending() {
+			l.PendingRefunded += amount
+			if hasCapture {
+				l.Captures[index].PendingRefunded += amount
+			}
+			continue
+		}
+		l.Refunded += amount
+		if hasCapture {
+			l.Captures[index].Refunded += amount
+		} else {
+			l.UnassignedRefunded += amount
+		}
+	}
+
+	return l, nil
+}
+
+// Capturable returns amount which may be captured.
+func (l *PaymentLedger) Capturable() int64 {
+	if l.Voided {
+		return 0
+	}
+	return nonNegative(l.Authorized - l.Captured - l.PendingCaptured)
+}
+
+// Refundable returns amount which may be refunded from all captures and charges.
+func (l *PaymentLedger) Refundable() int64 {
+	return nonNegative(l.Captured + l.Charged - l.Refunded - l.PendingRefunded)
+}
+
+// RefundableForCapture returns amount which may be refunded from given capture.
+func (l *PaymentLedger) RefundableForCapture(captureID string) int64 {
+	for _, capture := range l.Captures {
+		if capture.CaptureID == captureID {
+			refundable := capture.Refundable()
+			if total := l.Refundable(); total < refundable {
+				refundable = total
+			}
+			return refundable
+		}
+	}
+	return 0
+}
+
+// CheckCapture returns *BalanceError if amount can't be captured. Zero amount means the rest of authorized amount.
+func (l *PaymentLedger) CheckCapture(amount int64) error {
+	return checkBalance(PaymentActionCapture, amount, l.Capturable())
+}
+
+// CheckRefund returns *BalanceError if amount can't be refunded. If capture ID is given, amount is checked against
+// the capture balance. Zero amount means the rest of refundable amount.
+func (l *PaymentLedger) CheckRefund(amount int64, captureID string) error {
+	available := l.Refundable()
+	if captureID != "" {
+		available = l.RefundableForCapture(captureID)
+	}
+	return checkBalance(PaymentActionRefund, amount, available)
+}
+
+func checkBalance(action PaymentAction, amount, available int64) error {
+	if amount < 0 || amount > available || available == 0 {
+		return &BalanceError{Action: action, Requested: amount, Available: available}
+	}
+	return nil
+}
+
+func nonNegative(amount int64) int64 {
+	if amount < 0 {
+		return 0
+	}
+	return amount
+}
@@ -0,0 +1,102 @@
+package zooz
+
+import (
+	"context"
+	"testing"
+)
+
+func newLedgerTestPayment() *Payment {
+	succeed := Result{Status: ResultStatusSucceed}
+	return &Payment{
+		ID:     "payment_id",
+		Status: PaymentStatusCaptured,
+		RelatedResources: &PaymentRelatedResources{
+			Authorizations: []Authorization{
+				{ID: "declined", Amount: 1000, Result: Result{Status: ResultStatusFailed}},
+				{ID: "authorization", Amount: 1000, Result: succeed},
+			},
+			Captures: []Capture{
+				{ID: "capture1", CaptureParams: CaptureParams{Amount: 600}, Result: succeed},
+				{ID: "failed", CaptureParams: CaptureParams{Amount: 400}, Result: Result{Status: ResultStatusFailed}},
+				{ID: "capture2", CaptureParams: CaptureParams{Amount: 300}, Result: succeed},
+			},
+			Refunds: []Refund{
+				{ID: "refund1", RefundParams: RefundParams{Amount: 200, CaptureID: "capture1"}, Result: succeed},
+				{ID: "refund2", RefundParams: RefundParams{Amount: 100, CaptureID: "capture2"}, Result: Result{Status: ResultStatusPending}},
+				{ID: "refund3", RefundParams: RefundParams{Amount: 50}, Result: succeed},
+			},
+		},
+	}
+}
+
+func TestPaymentLedger(t *testing.T) {
+	l, err := NewPaymentLedger(newLedgerTestPayment())
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	if l.Authorized != 1000 || l.Captured != 900 || l.Refunded != 250 || l.PendingRefunded != 100 {
+		t.Errorf("Ledger is not as expected: %+v", l)
+	}
+	if l.Capturable() != 100 {
+		t.Errorf("Invalid capturable amount: %d", l.Capturable())
+	}
+	if l.Refundable() != 550 {
+		t.Errorf("Invalid refundable amount: %d", l.Refundable())
+	}
+	if l.RefundableForCapture("capture1") != 400 || l.RefundableForCapture("capture2") != 200 {
+		t.Errorf("Invalid capture balances: %+v", l.Captures)
+	}
+	if l.RefundableForCapture("failed") != 0 {
+		t.Errorf("Failed capture must not be refundable: %d", l.RefundableForCapture("failed"))
+	}
+
+	if err := l.CheckCapture(100); err != nil {
+		t.Errorf("Capture must be allowed: %s", err)
+	}
+	if err := l.CheckCapture(101); err == nil {
+		t.Error("Over-capture must not be allowed")
+	}
+	if err := l.CheckRefund(250, "capture2"); err == nil {
+		t.Error("Over-refund of capture must not be allowed")
+	}
+	if err := l.CheckRefund(0, ""); err != nil {
+		t.Errorf("Full refund must be allowed: %s", err)
+	}
+}
+
+func TestPaymentLedger_Voided(t *testing.T) {
+	l, err := NewPaymentLedger(&Payment{RelatedResources: &PaymentRelatedResources{
+		Authorizations: []Authorization{{Amount: 1000, Result: Result{Status: ResultStatusSucceed}}},
+		Voids:          []Void{{Result: Result{Status: ResultStatusSucceed}}},
+	}})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if err := l.CheckCapture(0); err == nil {
+		t.Error("Capture of voided payment must not be allowed")
+	}
+
+	if _, err := NewPaymentLedger(&Payment{ID: "id"}); err == nil {
+		t.Error("Payment without related resources must return error")
+	}
+}
+
+func TestGuardedClient_Refund_OverRefund(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.Get", "payment_id", []PaymentExpand{PaymentExpandAll}).Return(newLedgerTestPayment(), nil).Once()
+
+	// Payment requested without captures and refunds must not be used for balance check.
+	payment := &Payment{ID: "payment_id", Status: PaymentStatusCaptured, RelatedResources: &PaymentRelatedResources{
+		Authorizations: []Authorization{{Amount: 1000, Result: Result{Status: ResultStatusSucceed}}},
+	}}
+	_, err := NewGuardedClient(mock).Refund(context.Background(), "key", payment, &RefundParams{Amount: 1000})
+	balanceErr, ok := err.(*BalanceError)
+	if !ok {
+		t.Fatalf("Invalid error: %v", err)
+	}
+	if balanceErr.Available != 550 {
+		t.Errorf("Invalid error: %+v", balanceErr)
+	}
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,150 @@
+package zooz
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NextActionResult is a result of PaymentNextAction execution. Only the field corresponding to the action is set.
+type NextActionResult struct {
+	Action        PaymentAction
+	Authorization *Authorization
+	Charge        *Charge
+	Capture       *Capture
+	Void          *Void
+	Refund        *Refund
+	Payment       *Payment
+}
+
+// ResolveHref returns API path for given href. Href may be absolute or relative to API URL.
+// Error is returned for hrefs pointing outside of API, because client credentials must not be sent there.
+func ResolveHref(href string) (string, error) {
+	resolved, external, err := resolveHref(href)
+	if err != nil {
+		return "", err
+	}
+	if external {
+		return "", errors.Errorf("href %s points outside of API", href)
+	}
+	return strings.TrimPrefix(resolved.String(), apiURL), nil
+}
+
+func resolveHref(href string) (*url.URL, bool, error) {
+	if href == "" {
+		return nil, false, errors.New("href is empty")
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to parse href %s", href)
+	}
+	base, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to parse API URL")
+	}
+	resolved := base.ResolveReference(ref)
+	external := resolved.Scheme != base.Scheme || resolved.Host != base.Host
+	return resolved, external, nil
+}
+
+// Follow does GET request to given href from API response and decodes response into respObj.
+func (c *Client) Follow(ctx context.Context, href string, respObj interface{}) error {
+	path, err := ResolveHref(href)
+	if err != nil {
+		return err
+	}
+	return c.Call(ctx, "GET", path, nil, nil, respObj)
+}
+
+// FollowPaymentMethod returns PaymentMethod entity the link points to.
+func (c *Client) FollowPaymentMethod(ctx context.Context, link PaymentMethodHref) (*PaymentMethod, error) {
+	paymentMethod := &PaymentMethod{}
+	if err := c.Follow(ctx, link.Href, paymentMethod); err != nil {
+		return nil, err
+	}
+	return paymentMethod, nil
+}
+
+// FollowDocument returns content of provider document. Credentials are sent only if document is served by API.
+// Use OpenDocument to stream large documents.
+func (c *Client) FollowDocument(ctx context.Context, document ProviderDocument) (content []byte, followErr error) {
+	doc, err := c.OpenDocument(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := doc.Body.Close(); err != nil && followErr == nil {
+			content, followErr = nil, err
+		}
+	}()
+
+	body, err := ioutil.ReadAll(doc.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+	return body, nil
+}
+
+// ExecuteNextAction performs action from Payment.PossibleNextActions. Params must be of the type expected by
+// the action: *AuthorizationParams, *ChargeParams, *CaptureParams, *RefundParams, *PaymentParams for
+// "Update Payment", or nil for Void. Client info is sent for Authorize and Charge only.
+func (c *Client) ExecuteNextAction(ctx context.Context, idempotencyKey string, action PaymentNextAction, params interface{}, clientInfo *ClientInfo) (*NextActionResult, error) {
+	path, err := ResolveHref(action.Href)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NextActionResult{Action: action.Action}
+	method := "POST"
+	headers := map[string]string{headerIdempotencyKey: idempotencyKey}
+	var respObj interface{}
+	var validParams bool
+
+	switch action.Action {
+	case PaymentActionAuthorize:
+		_, validParams = params.(*AuthorizationParams)
+		result.Authorization = &Authorization{}
+		respObj = result.Authorization
+	case PaymentActionCharge:
+		_, validParams = params.(*ChargeParams)
+		result.Charge = &Charge{}
+		respObj = result.Charge
+	case PaymentActionCapture:
+		_, validParams = params.(*CaptureParams)
+		result.Capture = &Capture{}
+		respObj = result.Capture
+	case PaymentActionRefund:
+		_, validParams = params.(*RefundParams)
+		result.Refund = &Refund{}
+		respObj = result.Refund
+	case PaymentActionVoid:
+		validParams = params == nil
+		result.Void = &Void{}
+		respObj = result.Void
+	case PaymentActionUpdatePayment:
+		_, validParams = params.(*PaymentParams)
+		method = "PUT"
+		headers = nil
+		result.Payment = &Payment{}
+		respObj = result.Payment
+	default:
+		return nil, errors.Errorf("unknown payment action %q", action.Action)
+	}
+
+	if !validParams {
+		return nil, errors.Errorf("invalid params type %T for payment action %q", params, action.Action)
+	}
+
+	if clientInfo != nil && (action.Action == PaymentActionAuthorize || action.Action == PaymentActionCharge) {
+		headers[headerClientIPAddress] = clientInfo.IPAddress
+		headers[headerClientUserAgent] = clientInfo.UserAgent
+	}
+
+	if err := c.Call(ctx, method, path, headers, params, respObj); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
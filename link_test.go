@@ -0,0 +1,136 @@
+package zooz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestResolveHref(t *testing.T) {
+	tests := []struct {
+		href     string
+		expected string
+		invalid  bool
+	}{
+		{href: "https://api.paymentsos.com/payments/id/captures", expected: "payments/id/captures"},
+		{href: "/customers/id/payment-methods/token", expected: "customers/id/payment-methods/token"},
+		{href: "payments/id?expand=all", expected: "payments/id?expand=all"},
+		{href: "https://evil.example.com/payments/id", invalid: true},
+		{href: "http://api.paymentsos.com/payments/id", invalid: true},
+		{href: "", invalid: true},
+	}
+
+	for _, test := range tests {
+		path, err := ResolveHref(test.href)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("Href %q must be invalid, resolved to %q", test.href, path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Href %q resolving error: %s", test.href, err)
+		}
+		if path != test.expected {
+			t.Errorf("Href %q resolved to %q", test.href, path)
+		}
+	}
+}
+
+func TestClient_FollowPaymentMethod(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			if r.Method != "GET" || r.URL.String() != "https://api.paymentsos.com/customers/customer_id/payment-methods/token" {
+				t.Errorf("Invalid request: %s %s", r.Method, r.URL)
+			}
+			if r.Header.Get(headerPrivateKey) != "private_key" {
+				t.Errorf("Invalid request private key: %s", r.Header.Get(headerPrivateKey))
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"token":"token","last_4_digits":"1111"}`)),
+			}, nil
+		},
+	}
+
+	client := New(OptHTTPClient(httpClientMock), OptPrivateKey("private_key"))
+
+	paymentMethod, err := client.FollowPaymentMethod(context.Background(), PaymentMethodHref{
+		Href: "https://api.paymentsos.com/customers/customer_id/payment-methods/token",
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if paymentMethod.Token != "token" || paymentMethod.Last4Digits != "1111" {
+		t.Errorf("Payment method is not as expected: %+v", paymentMethod)
+	}
+}
+
+func TestClient_FollowDocument(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get(headerPrivateKey) != "" {
+				t.Error("Credentials must not be sent outside of API")
+			}
+			if r.Header.Get("Accept") != "application/pdf" {
+				t.Errorf("Invalid Accept header: %s", r.Header.Get("Accept"))
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("%PDF-1.4")),
+			}, nil
+		},
+	}
+
+	client := New(OptHTTPClient(httpClientMock), OptPrivateKey("private_key"))
+
+	content, err := client.FollowDocument(context.Background(), ProviderDocument{
+		ContentType: "application/pdf",
+		Href:        "https://provider.example.com/boleto.pdf",
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if string(content) != "%PDF-1.4" {
+		t.Errorf("Invalid content: %s", content)
+	}
+}
+
+func TestClient_ExecuteNextAction(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			if r.Method != "POST" || r.URL.Path != "/payments/id/captures" {
+				t.Errorf("Invalid request: %s %s", r.Method, r.URL)
+			}
+			if r.Header.Get(headerIdempotencyKey) != "key" {
+				t.Errorf("Invalid idempotency key: %s", r.Header.Get(headerIdempotencyKey))
+			}
+			var params CaptureParams
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Amount != 100 {
+				t.Errorf("Invalid request body: %+v, %v", params, err)
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"id":"capture_id","amount":100}`)),
+			}, nil
+		},
+	}
+
+	client := New(OptHTTPClient(httpClientMock))
+	action := PaymentNextAction{Action: PaymentActionCapture, Href: "https://api.paymentsos.com/payments/id/captures"}
+
+	result, err := client.ExecuteNextAction(context.Background(), "key", action, &CaptureParams{Amount: 100}, nil)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if result.Action != PaymentActionCapture || result.Capture == nil || result.Capture.ID != "capture_id" {
+		t.Errorf("Result is not as expected: %+v", result)
+	}
+
+	if _, err := client.ExecuteNextAction(context.Background(), "key", action, &RefundParams{}, nil); err == nil {
+		t.Error("Params of invalid type must return error")
+	}
+}
@@ -0,0 +1,489 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type mockArgument string
+
+// MockAnything may be used as expected argument of Mock.On to match any value.
+const MockAnything mockArgument = "zooz.MockAnything"
+
+// Mock is a programmable implementation of API for tests.
+// Program it with On(...).Return(...), pass it to your code as zooz.API and check it with AssertExpectations.
+// Calls are identified by "Entity.Method" names, e.g. "Payment.New" or "Refund.GetList", and the client's own
+// Call method is identified as "Call". Arguments are all method arguments except context.
+type Mock struct {
+	mu           sync.Mutex
+	expectations []*MockExpectation
+	calls        []MockCall
+}
+
+// MockCall is a record of one call made to Mock.
+type MockCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockExpectation is an expected call programmed with Mock.On.
+type MockExpectation struct {
+	method string
+	args   []interface{}
+	result interface{}
+	err    error
+	times  int
+	calls  int
+}
+
+// TestingT is a part of testing.TB used by Mock.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// NewMock creates empty Mock.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// On adds expectation of call with given method name and arguments. If no arguments given, any arguments match.
+// Use MockAnything to match any value of particular argument.
+func (m *Mock) On(method string, args ...interface{}) *MockExpectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &MockExpectation{method: method, args: args}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Return sets result and error returned by expected call. Result must be of type returned by the method
+// (e.g. *Payment for "Payment.New" or []Refund for "Refund.GetList"). For "Call" result is copied into respObj.
+func (e *MockExpectation) Return(result interface{}, err error) *MockExpectation {
+	e.result = result
+	e.err = err
+	return e
+}
+
+// Times limits number of calls matched by expectation. By default expectation matches any number of calls,
+// but at least one call is required by AssertExpectations.
+func (e *MockExpectation) Times(n int) *MockExpectation {
+	e.times = n
+	return e
+}
+
+// Once is a shortcut for Times(1).
+func (e *MockExpectation) Once() *MockExpectation {
+	return e.Times(1)
+}
+
+// Calls returns all calls made to Mock in order.
+func (m *Mock) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]MockCall(nil), m.calls...)
+}
+
+// CallsOf returns calls of given method made to Mock in order.
+func (m *Mock) CallsOf(method string) []MockCall {
+	var calls []MockCall
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AssertExpectations reports every expectation which wasn't called expected number of times.
+// Returns true if all expectations are met.
+func (m *Mock) AssertExpectations(t TestingT) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, e := range m.expectations {
+		switch {
+		case e.times > 0 && e.calls != e.times:
+			t.Errorf("zooz mock: %s%s expected to be called %d times, called %d times", e.method, formatMockArgs(e.args), e.times, e.calls)
+			ok = false
+		case e.times == 0 && e.calls == 0:
+			t.Errorf("zooz mock: %s%s expected to be called", e.method, formatMockArgs(e.args))
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (m *Mock) call(method string, args ...interface{}) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+
+	for _, e := range m.expectations {
+		if e.method != method || (e.times > 0 && e.calls >= e.times) || !matchMockArgs(e.args, args) {
+			continue
+		}
+		e.calls++
+		return e.result, e.err
+	}
+
+	return nil, errors.Errorf("zooz mock: unexpected call %s%s", method, formatMockArgs(args))
+}
+
+func matchMockArgs(expected, actual []interface{}) bool {
+	if len(expected) == 0 {
+		return true
+	}
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if expected[i] == MockAnything {
+			continue
+		}
+		if !reflect.DeepEqual(expected[i], actual[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatMockArgs(args []interface{}) string {
+	strs := make([]string, 0, len(args))
+	for _, arg := range args {
+		strs = append(strs, fmt.Sprintf("%+v", arg))
+	}
+	return "(" + strings.Join(strs, ", ") + ")"
+}
+
+// Call implements Caller interface.
+func (m *Mock) Call(ctx context.Context, method, path string, headers map[string]string, reqObj interface{}, respObj interface{}) error {
+	result, err := m.call("Call", method, path, headers, reqObj)
+	if result != nil && respObj != nil {
+		if setErr := setMockResult(respObj, result); setErr != nil {
+			return setErr
+		}
+	}
+	return err
+}
+
+// setMockResult copies programmed result to respObj. Result may be a pointer of the same type as respObj, or a value
+// of the type respObj points to.
+func setMockResult(respObj interface{}, result interface{}) error {
+	respValue := reflect.ValueOf(respObj)
+	if respValue.Kind() != reflect.Ptr || respValue.IsNil() {
+		return errors.Errorf("zooz mock: response object must be a non-nil pointer, got %T", respObj)
+	}
+
+	resultValue := reflect.ValueOf(result)
+	switch {
+	case resultValue.Type() == respValue.Type():
+		if !resultValue.IsNil() {
+			respValue.Elem().Set(resultValue.Elem())
+		}
+	case resultValue.Type().AssignableTo(respValue.Elem().Type()):
+		respValue.Elem().Set(resultValue)
+	default:
+		return errors.Errorf("zooz mock: result of type %T can't be assigned to response object of type %T", result, respObj)
+	}
+	return nil
+}
+
+// mockResultError reports programmed result which has not the type returned by the method.
+func mockResultError(method string, result interface{}, expected interface{}) error {
+	return errors.Errorf("zooz mock: result of %s must be of type %T, got %T", method, expected, result)
+}
+
+// Payment implements API interface.
+func (m *Mock) Payment() PaymentAPI { return mockPayment{m} }
+
+// Customer implements API interface.
+func (m *Mock) Customer() CustomerAPI { return mockCustomer{m} }
+
+// PaymentMethod implements API interface.
+func (m *Mock) PaymentMethod() PaymentMethodAPI { return mockPaymentMethod{m} }
+
+// Authorization implements API interface.
+func (m *Mock) Authorization() AuthorizationAPI { return mockAuthorization{m} }
+
+// Charge implements API interface.
+func (m *Mock) Charge() ChargeAPI { return mockCharge{m} }
+
+// Capture implements API interface.
+func (m *Mock) Capture() CaptureAPI { return mockCapture{m} }
+
+// Void implements API interface.
+func (m *Mock) Void() VoidAPI { return mockVoid{m} }
+
+// Refund implements API interface.
+func (m *Mock) Refund() RefundAPI { return mockRefund{m} }
+
+// Redirection implements API interface.
+func (m *Mock) Redirection() RedirectionAPI { return mockRedirection{m} }
+
+var _ API = (*Mock)(nil)
+
+type mockPayment struct{ m *Mock }
+
+func (p mockPayment) New(ctx context.Context, idempotencyKey string, params *PaymentParams) (*Payment, error) {
+	result, err := p.m.call("Payment.New", idempotencyKey, params)
+	payment, ok := result.(*Payment)
+	if !ok && result != nil {
+		return nil, mockResultError("Payment.New", result, payment)
+	}
+	return payment, err
+}
+
+func (p mockPayment) Get(ctx context.Context, id string, expands ...PaymentExpand) (*Payment, error) {
+	result, err := p.m.call("Payment.Get", id, expands)
+	payment, ok := result.(*Payment)
+	if !ok && result != nil {
+		return nil, mockResultError("Payment.Get", result, payment)
+	}
+	return payment, err
+}
+
+func (p mockPayment) Update(ctx context.Context, id string, params *PaymentParams) (*Payment, error) {
+	result, err := p.m.call("Payment.Update", id, params)
+	payment, ok := result.(*Payment)
+	if !ok && result != nil {
+		return nil, mockResultError("Payment.Update", result, payment)
+	}
+	return payment, err
+}
+
+type mockCustomer struct{ m *Mock }
+
+func (c mockCustomer) New(ctx context.Context, idempotencyKey string, params *CustomerParams) (*Customer, error) {
+	result, err := c.m.call("Customer.New", idempotencyKey, params)
+	customer, ok := result.(*Customer)
+	if !ok && result != nil {
+		return nil, mockResultError("Customer.New", result, customer)
+	}
+	return customer, err
+}
+
+func (c mockCustomer) Get(ctx context.Context, id string) (*Customer, error) {
+	result, err := c.m.call("Customer.Get", id)
+	customer, ok := result.(*Customer)
+	if !ok && result != nil {
+		return nil, mockResultError("Customer.Get", result, customer)
+	}
+	return customer, err
+}
+
+func (c mockCustomer) Update(ctx context.Context, id string, params *CustomerParams) (*Customer, error) {
+	result, err := c.m.call("Customer.Update", id, params)
+	customer, ok := result.(*Customer)
+	if !ok && result != nil {
+		return nil, mockResultError("Customer.Update", result, customer)
+	}
+	return customer, err
+}
+
+func (c mockCustomer) Delete(ctx context.Context, id string) error {
+	_, err := c.m.call("Customer.Delete", id)
+	return err
+}
+
+type mockPaymentMethod struct{ m *Mock }
+
+func (p mockPaymentMethod) New(ctx context.Context, idempotencyKey string, customerID string, token string) (*PaymentMethod, error) {
+	result, err := p.m.call("PaymentMethod.New", idempotencyKey, customerID, token)
+	paymentMethod, ok := result.(*PaymentMethod)
+	if !ok && result != nil {
+		return nil, mockResultError("PaymentMethod.New", result, paymentMethod)
+	}
+	return paymentMethod, err
+}
+
+func (p mockPaymentMethod) Get(ctx context.Context, customerID string, token string) (*PaymentMethod, error) {
+	result, err := p.m.call("PaymentMethod.Get", customerID, token)
+	paymentMethod, ok := result.(*PaymentMethod)
+	if !ok && result != nil {
+		return nil, mockResultError("PaymentMethod.Get", result, paymentMethod)
+	}
+	return paymentMethod, err
+}
+
+func (p mockPaymentMethod) GetList(ctx context.Context, customerID string) ([]PaymentMethod, error) {
+	result, err := p.m.call("PaymentMethod.GetList", customerID)
+	paymentMethods, ok := result.([]PaymentMethod)
+	if !ok && result != nil {
+		return nil, mockResultError("PaymentMethod.GetList", result, paymentMethods)
+	}
+	return paymentMethods, err
+}
+
+type mockAuthorization struct{ m *Mock }
+
+func (a mockAuthorization) New(ctx context.Context, idempotencyKey string, paymentID string, params *AuthorizationParams, clientInfo *ClientInfo) (*Authorization, error) {
+	result, err := a.m.call("Authorization.New", idempotencyKey, paymentID, params, clientInfo)
+	authorization, ok := result.(*Authorization)
+	if !ok && result != nil {
+		return nil, mockResultError("Authorization.New", result, authorization)
+	}
+	return authorization, err
+}
+
+func (a mockAuthorization) Get(ctx context.Context, paymentID string, authorizationID string) (*Authorization, error) {
+	result, err := a.m.call("Authorization.Get", paymentID, authorizationID)
+	authorization, ok := result.(*Authorization)
+	if !ok && result != nil {
+		return nil, mockResultError("Authorization.Get", result, authorization)
+	}
+	return authorization, err
+}
+
+func (a mockAuthorization) GetList(ctx context.Context, paymentID string) ([]Authorization, error) {
+	result, err := a.m.call("Authorization.GetList", paymentID)
+	authorizations, ok := result.([]Authorization)
+	if !ok && result != nil {
+		return nil, mockResultError("Authorization.GetList", result, authorizations)
+	}
+	return authorizations, err
+}
+
+type mockCharge struct{ m *Mock }
+
+func (c mockCharge) New(ctx context.Context, idempotencyKey string, paymentID string, params *ChargeParams, clientInfo *ClientInfo) (*Charge, error) {
+	result, err := c.m.call("Charge.New", idempotencyKey, paymentID, params, clientInfo)
+	charge, ok := result.(*Charge)
+	if !ok && result != nil {
+		return nil, mockResultError("Charge.New", result, charge)
+	}
+	return charge, err
+}
+
+func (c mockCharge) Get(ctx context.Context, paymentID string, chargeID string) (*Charge, error) {
+	result, err := c.m.call("Charge.Get", paymentID, chargeID)
+	charge, ok := result.(*Charge)
+	if !ok && result != nil {
+		return nil, mockResultError("Charge.Get", result, charge)
+	}
+	return charge, err
+}
+
+func (c mockCharge) GetList(ctx context.Context, paymentID string) ([]Charge, error) {
+	result, err := c.m.call("Charge.GetList", paymentID)
+	charges, ok := result.([]Charge)
+	if !ok && result != nil {
+		return nil, mockResultError("Charge.GetList", result, charges)
+	}
+	return charges, err
+}
+
+type mockCapture struct{ m *Mock }
+
+func (c mockCapture) New(ctx context.Context, idempotencyKey string, paymentID string, params *CaptureParams) (*Capture, error) {
+	result, err := c.m.call("Capture.New", idempotencyKey, paymentID, params)
+	capture, ok := result.(*Capture)
+	if !ok && result != nil {
+		return nil, mockResultError("Capture.New", result, capture)
+	}
+	return capture, err
+}
+
+func (c mockCapture) Get(ctx context.Context, paymentID string, captureID string) (*Capture, error) {
+	result, err := c.m.call("Capture.Get", paymentID, captureID)
+	capture, ok := result.(*Capture)
+	if !ok && result != nil {
+		return nil, mockResultError("Capture.Get", result, capture)
+	}
+	return capture, err
+}
+
+func (c mockCapture) GetList(ctx context.Context, paymentID string) ([]Capture, error) {
+	result, err := c.m.call("Capture.GetList", paymentID)
+	captures, ok := result.([]Capture)
+	if !ok && result != nil {
+		return nil, mockResultError("Capture.GetList", result, captures)
+	}
+	return captures, err
+}
+
+type mockVoid struct{ m *Mock }
+
+func (v mockVoid) New(ctx context.Context, idempotencyKey string, paymentID string) (*Void, error) {
+	result, err := v.m.call("Void.New", idempotencyKey, paymentID)
+	void, ok := result.(*Void)
+	if !ok && result != nil {
+		return nil, mockResultError("Void.New", result, void)
+	}
+	return void, err
+}
+
+func (v mockVoid) Get(ctx context.Context, paymentID string, voidID string) (*Void, error) {
+	result, err := v.m.call("Void.Get", paymentID, voidID)
+	void, ok := result.(*Void)
+	if !ok && result != nil {
+		return nil, mockResultError("Void.Get", result, void)
+	}
+	return void, err
+}
+
+func (v mockVoid) GetList(ctx context.Context, paymentID string) ([]Void, error) {
+	result, err := v.m.call("Void.GetList", paymentID)
+	voids, ok := result.([]Void)
+	if !ok && result != nil {
+		return nil, mockResultError("Void.GetList", result, voids)
+	}
+	return voids, err
+}
+
+type mockRefund struct{ m *Mock }
+
+func (r mockRefund) New(ctx context.Context, idempotencyKey string, paymentID string, params *RefundParams) (*Refund, error) {
+	result, err := r.m.call("Refund.New", idempotencyKey, paymentID, params)
+	refund, ok := result.(*Refund)
+	if !ok && result != nil {
+		return nil, mockResultError("Refund.New", result, refund)
+	}
+	return refund, err
+}
+
+func (r mockRefund) Get(ctx context.Context, paymentID string, refundID string) (*Refund, error) {
+	result, err := r.m.call("Refund.Get", paymentID, refundID)
+	refund, ok := result.(*Refund)
+	if !ok && result != nil {
+		return nil, mockResultError("Refund.Get", result, refund)
+	}
+	return refund, err
+}
+
+func (r mockRefund) GetList(ctx context.Context, paymentID string) ([]Refund, error) {
+	result, err := r.m.call("Refund.GetList", paymentID)
+	refunds, ok := result.([]Refund)
+	if !ok && result != nil {
+		return nil, mockResultError("Refund.GetList", result, refunds)
+	}
+	return refunds, err
+}
+
+type mockRedirection struct{ m *Mock }
+
+func (r mockRedirection) Get(ctx context.Context, paymentID string, redirectionID string) (*Redirection, error) {
+	result, err := r.m.call("Redirection.Get", paymentID, redirectionID)
+	redirection, ok := result.(*Redirection)
+	if !ok && result != nil {
+		return nil, mockResultError("Redirection.Get", result, redirection)
+	}
+	return redirection, err
+}
+
+func (r mockRedirection) GetList(ctx context.Context, paymentID string) ([]Redirection, error) {
+	result, err := r.m.call("Redirection.GetList", paymentID)
+	redirections, ok := result.([]Redirection)
+	if !ok && result != nil {
+		return nil, mockResultError("Redirection.GetList", result, redirections)
+	}
+	return redirections, err
+}
@@ -0,0 +1,132 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type testingTMock struct {
+	errors []string
+}
+
+func (t *testingTMock) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMock_Expectations(t *testing.T) {
+	m := NewMock()
+	m.On("Payment.New", "idempotency_key", MockAnything).Return(&Payment{ID: "payment_id"}, nil).Once()
+	m.On("Capture.New", MockAnything, "payment_id", &CaptureParams{Amount: 100}).Return(nil, errors.New("capture_error"))
+
+	var api API = m
+
+	payment, err := api.Payment().New(context.Background(), "idempotency_key", &PaymentParams{Amount: 100})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if payment.ID != "payment_id" {
+		t.Errorf("Payment is not as expected: %+v", payment)
+	}
+
+	capture, err := api.Capture().New(context.Background(), "key", "payment_id", &CaptureParams{Amount: 100})
+	if capture != nil {
+		t.Errorf("Capture must be nil: %+v", capture)
+	}
+	if err == nil || err.Error() != "capture_error" {
+		t.Errorf("Invalid error: %v", err)
+	}
+
+	if !m.AssertExpectations(t) {
+		t.Error("Expectations must be met")
+	}
+
+	calls := m.Calls()
+	if len(calls) != 2 || calls[0].Method != "Payment.New" || calls[1].Method != "Capture.New" {
+		t.Errorf("Invalid calls: %+v", calls)
+	}
+	if calls[1].Args[1] != "payment_id" {
+		t.Errorf("Invalid call args: %+v", calls[1].Args)
+	}
+}
+
+func TestMock_UnexpectedCall(t *testing.T) {
+	m := NewMock()
+	m.On("Payment.New").Return(&Payment{ID: "payment_id"}, nil).Once()
+
+	if _, err := m.Payment().New(context.Background(), "key1", nil); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if _, err := m.Payment().New(context.Background(), "key2", nil); err == nil {
+		t.Error("Call over limit must return error")
+	}
+	if _, err := m.Refund().GetList(context.Background(), "payment_id"); err == nil {
+		t.Error("Unexpected call must return error")
+	}
+	if len(m.CallsOf("Payment.New")) != 2 {
+		t.Errorf("Invalid calls: %+v", m.Calls())
+	}
+}
+
+func TestMock_AssertExpectations(t *testing.T) {
+	m := NewMock()
+	m.On("Void.New").Return(&Void{ID: "void_id"}, nil).Times(2)
+	m.On("Customer.Delete", "customer_id").Return(nil, nil)
+
+	if _, err := m.Void().New(context.Background(), "key", "payment_id"); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	mockT := &testingTMock{}
+	if m.AssertExpectations(mockT) {
+		t.Error("Expectations must not be met")
+	}
+	if len(mockT.errors) != 2 {
+		t.Errorf("Invalid reported errors: %q", mockT.errors)
+	}
+}
+
+func TestMock_Call(t *testing.T) {
+	m := NewMock()
+	m.On("Call", "GET", "payments/id", MockAnything, nil).Return(&Payment{ID: "id"}, nil)
+
+	payment := &Payment{}
+	if err := m.Call(context.Background(), "GET", "payments/id", nil, nil, payment); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if payment.ID != "id" {
+		t.Errorf("Payment is not as expected: %+v", payment)
+	}
+}
+
+func TestMock_Call_ResultType(t *testing.T) {
+	m := NewMock()
+	m.On("Call", "GET", "payments/id/voids", MockAnything, nil).Return([]Void{{ID: "void"}}, nil).Once()
+	m.On("Call", "GET", "payments/id", MockAnything, nil).Return(&Refund{ID: "refund"}, nil).Once()
+
+	var voids []Void
+	if err := m.Call(context.Background(), "GET", "payments/id/voids", nil, nil, &voids); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if len(voids) != 1 || voids[0].ID != "void" {
+		t.Errorf("Voids are not as expected: %+v", voids)
+	}
+
+	payment := &Payment{}
+	if err := m.Call(context.Background(), "GET", "payments/id", nil, nil, payment); err == nil {
+		t.Error("Error expected for result of invalid type")
+	}
+}
+
+func TestMock_ResultType(t *testing.T) {
+	m := NewMock()
+	m.On("Payment.Get").Return(&Refund{ID: "refund"}, nil).Once()
+
+	_, err := m.Payment().Get(context.Background(), "id")
+	if err == nil || !strings.Contains(err.Error(), "Payment.Get") || !strings.Contains(err.Error(), "*zooz.Refund") {
+		t.Errorf("Error expected for result of invalid type: %v", err)
+	}
+}
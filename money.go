@@ -0,0 +1,266 @@
+package zooz
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// currencyExponents maps ISO 4217 currency codes to number of digits after the decimal separator (minor units).
+var currencyExponents = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BRL": 2,
+	"BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHF": 2, "CLF": 4, "CLP": 0,
+	"CNY": 2, "COP": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2,
+	"EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2,
+	"GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3, "JPY": 0, "KES": 2, "KGS": 2, "KHR": 2,
+	"KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2,
+	"LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2,
+	"MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2,
+	"NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0,
+	"QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0, "SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2,
+	"SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2,
+	"THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2,
+	"UGX": 0, "USD": 2, "UYI": 0, "UYU": 2, "UYW": 4, "UZS": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2,
+	"XAF": 0, "XCD": 2, "XOF": 0, "XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWL": 2,
+}
+
+// CurrencyExponent returns number of minor unit digits of ISO 4217 currency, e.g. 2 for USD, 0 for JPY and 3 for KWD.
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// ValidateCurrency returns error if currency is not a known ISO 4217 code.
+func ValidateCurrency(currency string) error {
+	if _, ok := currencyExponents[currency]; !ok {
+		return errors.Errorf("unknown currency %q", currency)
+	}
+	return nil
+}
+
+// Money is an amount in minor units of ISO 4217 currency, e.g. {1050, "USD"} is 10.50 USD and
+// {1050, "JPY"} is 1050 JPY. API amounts are always in minor units.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney creates Money with given amount in minor units and validated currency.
+func NewMoney(amount int64, currency string) (Money, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// ParseMoney parses decimal amount in major units, e.g. "10.50" USD, "1050" JPY or "1.005" KWD.
+// Error is returned if amount has more fractional digits than currency allows.
+func ParseMoney(amount string, currency string) (Money, error) {
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, errors.Errorf("unknown currency %q", currency)
+	}
+
+	value := strings.TrimSpace(amount)
+	negative := false
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	parts := strings.SplitN(value, ".", 2)
+	whole, fraction := parts[0], ""
+	if len(parts) == 2 {
+		fraction = parts[1]
+	}
+	if whole == "" && fraction == "" {
+		return Money{}, errors.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > exponent {
+		return Money{}, errors.Errorf("amount %q has more than %d fractional digits allowed for %s", amount, exponent, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	digits := whole + fraction
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Money{}, errors.Errorf("invalid amount %q", amount)
+		}
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, errors.Wrapf(err, "invalid amount %q", amount)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// Decimal returns amount in major units with currency exponent digits after decimal separator, e.g. "10.50".
+func (m Money) Decimal() string {
+	exponent, ok := CurrencyExponent(m.Currency)
+	if !ok {
+		exponent = 2
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absInt64(amount), 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String implements stringer interface.
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// IsZero reports whether amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether amount is negative.
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns sum of amounts. Error is returned for different currencies and overflow.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, errors.Errorf("overflow adding %s to %s", other, m)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns difference of amounts. Error is returned for different currencies and overflow.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, errors.Errorf("overflow subtracting %s from %s", other, m)
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul returns amount multiplied by n. Error is returned for overflow.
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Amount: 0, Currency: m.Currency}, nil
+	}
+	product := m.Amount * n
+	if product/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, errors.Errorf("overflow multiplying %s by %d", m, n)
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Cmp compares amounts and returns -1, 0 or +1. Error is returned for different currencies.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) checkCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return errors.Errorf("currency mismatch: %s and %s", m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// Money returns payment amount and currency as Money.
+func (p *PaymentParams) Money() (Money, error) {
+	return NewMoney(p.Amount, p.Currency)
+}
+
+// SetMoney sets payment amount and currency.
+func (p *PaymentParams) SetMoney(m Money) error {
+	if err := ValidateCurrency(m.Currency); err != nil {
+		return err
+	}
+	p.Amount, p.Currency = m.Amount, m.Currency
+	return nil
+}
+
+// Money returns capture amount as Money. Capture has no currency, so currency of the payment must be given.
+func (c *CaptureParams) Money(currency string) (Money, error) {
+	return NewMoney(c.Amount, currency)
+}
+
+// SetMoney sets capture amount. Error is returned if currency differs from payment currency.
+func (c *CaptureParams) SetMoney(m Money, paymentCurrency string) error {
+	if m.Currency != paymentCurrency {
+		return errors.Errorf("capture currency %s differs from payment currency %s", m.Currency, paymentCurrency)
+	}
+	c.Amount = m.Amount
+	return nil
+}
+
+// Money returns refund amount as Money. Refund has no currency, so currency of the payment must be given.
+func (r *RefundParams) Money(currency string) (Money, error) {
+	return NewMoney(r.Amount, currency)
+}
+
+// SetMoney sets refund amount. Error is returned if currency differs from payment currency.
+func (r *RefundParams) SetMoney(m Money, paymentCurrency string) error {
+	if m.Currency != paymentCurrency {
+		return errors.Errorf("refund currency %s differs from payment currency %s", m.Currency, paymentCurrency)
+	}
+	r.Amount = m.Amount
+	return nil
+}
+
+// FirstPayment returns first installment amount as Money in currency of the payment.
+func (i *Installments) FirstPayment(currency string) (Money, error) {
+	return NewMoney(i.FirstPaymentAmount, currency)
+}
+
+// RemainingPayments returns amount of each remaining installment as Money in currency of the payment.
+func (i *Installments) RemainingPayments(currency string) (Money, error) {
+	return NewMoney(i.RemainingPaymentsAmount, currency)
+}
+
+// SetMoney sets installment amounts. Error is returned if amounts are in different currencies.
+func (i *Installments) SetMoney(first, remaining Money) error {
+	if err := first.checkCurrency(remaining); err != nil {
+		return err
+	}
+	i.FirstPaymentAmount, i.RemainingPaymentsAmount = first.Amount, remaining.Amount
+	return nil
+}
@@ -0,0 +1,168 @@
+package zooz
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		expected int64
+		err      bool
+	}{
+		{amount: "10.50", currency: "USD", expected: 1050},
+		{amount: "10.5", currency: "USD", expected: 1050},
+		{amount: "10", currency: "USD", expected: 1000},
+		{amount: ".05", currency: "USD", expected: 5},
+		{amount: "-1.01", currency: "EUR", expected: -101},
+		{amount: "1050", currency: "JPY", expected: 1050},
+		{amount: "1.005", currency: "KWD", expected: 1005},
+		{amount: "+1.01", currency: "EUR", expected: 101},
+		{amount: "0", currency: "USD", expected: 0},
+		{amount: "0.00", currency: "USD", expected: 0},
+		{amount: "92233720368547758.07", currency: "USD", expected: 9223372036854775807},
+		{amount: "9223372036854775807", currency: "JPY", expected: 9223372036854775807},
+		{amount: "10.505", currency: "USD", err: true},
+		{amount: "10.5", currency: "JPY", err: true},
+		{amount: "1O.50", currency: "USD", err: true},
+		{amount: "", currency: "USD", err: true},
+		{amount: "-+5", currency: "USD", err: true},
+		{amount: "+-5", currency: "USD", err: true},
+		{amount: "--5", currency: "USD", err: true},
+		{amount: "10", currency: "XXX", err: true},
+		{amount: "99999999999999999999", currency: "USD", err: true},
+		{amount: "92233720368547758.08", currency: "USD", err: true},
+	}
+
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.amount, tt.currency)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Error expected for %q %s, got %s", tt.amount, tt.currency, m)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error must be nil for %q %s: %s", tt.amount, tt.currency, err)
+			continue
+		}
+		if m.Amount != tt.expected || m.Currency != tt.currency {
+			t.Errorf("Money for %q %s is %+v, expected %d", tt.amount, tt.currency, m, tt.expected)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{money: Money{1050, "USD"}, expected: "10.50"},
+		{money: Money{5, "USD"}, expected: "0.05"},
+		{money: Money{-5, "USD"}, expected: "-0.05"},
+		{money: Money{1050, "JPY"}, expected: "1050"},
+		{money: Money{1005, "KWD"}, expected: "1.005"},
+		{money: Money{1, "CLF"}, expected: "0.0001"},
+		{money: Money{math.MinInt64, "USD"}, expected: "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.expected {
+			t.Errorf("Decimal of %+v is %q, expected %q", tt.money, got, tt.expected)
+		}
+	}
+
+	if s := (Money{1050, "USD"}).String(); s != "10.50 USD" {
+		t.Errorf("String is %q", s)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := Money{1000, "USD"}
+	b := Money{250, "USD"}
+
+	if sum, err := a.Add(b); err != nil || sum != (Money{1250, "USD"}) {
+		t.Errorf("Add result is %+v, %v", sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || diff != (Money{-750, "USD"}) || !diff.IsNegative() {
+		t.Errorf("Sub result is %+v, %v", diff, err)
+	}
+	if product, err := b.Mul(3); err != nil || product != (Money{750, "USD"}) {
+		t.Errorf("Mul result is %+v, %v", product, err)
+	}
+	if cmp, err := a.Cmp(b); err != nil || cmp != 1 {
+		t.Errorf("Cmp result is %d, %v", cmp, err)
+	}
+
+	eur := Money{100, "EUR"}
+	if _, err := a.Add(eur); err == nil {
+		t.Errorf("Error expected for mixed currencies in Add")
+	}
+	if _, err := a.Sub(eur); err == nil {
+		t.Errorf("Error expected for mixed currencies in Sub")
+	}
+	if _, err := a.Cmp(eur); err == nil {
+		t.Errorf("Error expected for mixed currencies in Cmp")
+	}
+
+	max := Money{math.MaxInt64, "USD"}
+	if _, err := max.Add(Money{1, "USD"}); err == nil {
+		t.Errorf("Error expected for overflow in Add")
+	}
+	if _, err := (Money{math.MinInt64, "USD"}).Sub(Money{1, "USD"}); err == nil {
+		t.Errorf("Error expected for overflow in Sub")
+	}
+	if _, err := max.Mul(2); err == nil {
+		t.Errorf("Error expected for overflow in Mul")
+	}
+}
+
+func TestMoneyParamsConversion(t *testing.T) {
+	m, _ := ParseMoney("12.34", "EUR")
+
+	var payment PaymentParams
+	if err := payment.SetMoney(m); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if payment.Amount != 1234 || payment.Currency != "EUR" {
+		t.Errorf("Payment params are %+v", payment)
+	}
+	if got, err := payment.Money(); err != nil || got != m {
+		t.Errorf("Payment money is %+v, %v", got, err)
+	}
+	if err := payment.SetMoney(Money{1, "XXX"}); err == nil {
+		t.Errorf("Error expected for unknown currency")
+	}
+
+	var capture CaptureParams
+	if err := capture.SetMoney(m, "EUR"); err != nil || capture.Amount != 1234 {
+		t.Errorf("Capture params are %+v, %v", capture, err)
+	}
+	if err := capture.SetMoney(m, "USD"); err == nil {
+		t.Errorf("Error expected for capture currency mismatch")
+	}
+
+	var refund RefundParams
+	if err := refund.SetMoney(m, "EUR"); err != nil || refund.Amount != 1234 {
+		t.Errorf("Refund params are %+v, %v", refund, err)
+	}
+	if got, err := refund.Money("EUR"); err != nil || got != m {
+		t.Errorf("Refund money is %+v, %v", got, err)
+	}
+
+	var installments Installments
+	if err := installments.SetMoney(Money{500, "EUR"}, Money{250, "EUR"}); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if first, _ := installments.FirstPayment("EUR"); first.Amount != 500 {
+		t.Errorf("First installment is %+v", first)
+	}
+	if remaining, _ := installments.RemainingPayments("EUR"); remaining.Amount != 250 {
+		t.Errorf("Remaining installments are %+v", remaining)
+	}
+	if err := installments.SetMoney(Money{500, "EUR"}, Money{250, "USD"}); err == nil {
+		t.Errorf("Error expected for installments currency mismatch")
+	}
+}
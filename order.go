@@ -0,0 +1,104 @@
+package zooz
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Subtotal returns quantity * unit price of line item without discount and tax.
+func (i PaymentOrderLineItem) Subtotal() int64 {
+	return i.Quantity * i.UnitPrice
+}
+
+// Total returns quantity * unit price - discount + tax of line item.
+func (i PaymentOrderLineItem) Total() int64 {
+	return i.Subtotal() - i.DiscountAmount + i.TaxAmount
+}
+
+// Total returns amount of the order: line items without tax, tax of the order (or sum of line items tax, if order
+// tax amount is not set), shipping and duty, minus order discount.
+func (o *PaymentOrder) Total() int64 {
+	var total, lineTax int64
+	for _, item := range o.LineItems {
+		total += item.Subtotal() - item.DiscountAmount
+		lineTax += item.TaxAmount
+	}
+	tax := o.TaxAmount
+	if tax == 0 {
+		tax = lineTax
+	}
+	return total + tax + o.ShippingAmount + o.DutyAmount - o.DiscountAmount
+}
+
+// PaymentOrderBuilder builds order with calculated line totals and tax amount.
+type PaymentOrderBuilder struct {
+	order PaymentOrder
+}
+
+// NewPaymentOrderBuilder creates builder of order with given ID.
+func NewPaymentOrderBuilder(id string) *PaymentOrderBuilder {
+	return &PaymentOrderBuilder{order: PaymentOrder{ID: id}}
+}
+
+// AddItem adds line item. Its total amount is calculated.
+func (b *PaymentOrderBuilder) AddItem(item PaymentOrderLineItem) *PaymentOrderBuilder {
+	item.TotalAmount = item.Total()
+	b.order.LineItems = append(b.order.LineItems, item)
+	b.order.TaxAmount += item.TaxAmount
+	return b
+}
+
+// Tax adds order-level tax, e.g. tax which is not split by line items.
+func (b *PaymentOrderBuilder) Tax(amount int64) *PaymentOrderBuilder {
+	b.order.TaxAmount += amount
+	return b
+}
+
+// Shipping sets shipping amount.
+func (b *PaymentOrderBuilder) Shipping(amount int64) *PaymentOrderBuilder {
+	b.order.ShippingAmount = amount
+	return b
+}
+
+// Duty sets duty amount.
+func (b *PaymentOrderBuilder) Duty(amount int64) *PaymentOrderBuilder {
+	b.order.DutyAmount = amount
+	return b
+}
+
+// Discount sets order-level discount amount.
+func (b *PaymentOrderBuilder) Discount(amount int64) *PaymentOrderBuilder {
+	b.order.DiscountAmount = amount
+	return b
+}
+
+// Total returns total amount of the order being built.
+func (b *PaymentOrderBuilder) Total() int64 {
+	return b.order.Total()
+}
+
+// Build validates and returns the order.
+func (b *PaymentOrderBuilder) Build() (*PaymentOrder, error) {
+	order := b.order
+	order.LineItems = append([]PaymentOrderLineItem(nil), b.order.LineItems...)
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ApplyTo builds the order and sets it to payment params. If payment amount is zero, it is set to order total,
+// otherwise error is returned if amount doesn't match the total.
+func (b *PaymentOrderBuilder) ApplyTo(params *PaymentParams) error {
+	order, err := b.Build()
+	if err != nil {
+		return err
+	}
+	total := order.Total()
+	if params.Amount == 0 {
+		params.Amount = total
+	} else if params.Amount != total {
+		return errors.Errorf("order total %d doesn't match payment amount %d", total, params.Amount)
+	}
+	params.Order = order
+	return nil
+}
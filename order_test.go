@@ -0,0 +1,79 @@
+package zooz
+
+import (
+	"testing"
+)
+
+func TestPaymentOrder_Total(t *testing.T) {
+	order := &PaymentOrder{
+		ShippingAmount: 500,
+		DutyAmount:     200,
+		DiscountAmount: 300,
+		LineItems: []PaymentOrderLineItem{
+			{Quantity: 2, UnitPrice: 1000, DiscountAmount: 100, TaxAmount: 190},
+			{Quantity: 1, UnitPrice: 500, TaxAmount: 50},
+		},
+	}
+
+	// 2000 - 100 + 500 + tax 240 + shipping 500 + duty 200 - discount 300
+	if total := order.Total(); total != 3040 {
+		t.Errorf("Total is %d", total)
+	}
+	if total := order.LineItems[0].Total(); total != 2090 {
+		t.Errorf("Line item total is %d", total)
+	}
+
+	order.TaxAmount = 240
+	if total := order.Total(); total != 3040 {
+		t.Errorf("Total with order tax is %d", total)
+	}
+	if err := order.Validate(); err != nil {
+		t.Errorf("Order must be valid: %s", err)
+	}
+
+	order.TaxAmount = 100
+	order.LineItems[1].TotalAmount = 1
+	order.LineItems[1].DiscountAmount = 600
+	fields := validationFields(t, order.Validate())
+	expected := []string{"line_items[1].discount_amount", "line_items[1].total_amount", "tax_amount"}
+	if !equalFields(fields, expected) {
+		t.Errorf("Invalid fields are %v, expected %v", fields, expected)
+	}
+}
+
+func TestPaymentOrderBuilder(t *testing.T) {
+	b := NewPaymentOrderBuilder("order").
+		AddItem(PaymentOrderLineItem{SKU: "A-1", CommodityCode: "44121600", UnitOfMeasure: "EA", Quantity: 3, UnitPrice: 400, DiscountAmount: 200, TaxAmount: 100}).
+		AddItem(PaymentOrderLineItem{SKU: "B-2", Quantity: 1, UnitPrice: 1000}).
+		Tax(50).
+		Shipping(300).
+		Duty(100).
+		Discount(250)
+
+	if total := b.Total(); total != 2300 {
+		t.Errorf("Total is %d", total)
+	}
+
+	params := &PaymentParams{Currency: "USD"}
+	if err := b.ApplyTo(params); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if params.Amount != 2300 || params.Order == nil || params.Order.TaxAmount != 150 {
+		t.Errorf("Params are not as expected: %+v %+v", params, params.Order)
+	}
+	if params.Order.LineItems[0].TotalAmount != 1100 || params.Order.LineItems[1].TotalAmount != 1000 {
+		t.Errorf("Line items are not as expected: %+v", params.Order.LineItems)
+	}
+	if err := params.Validate(); err != nil {
+		t.Errorf("Params must be valid: %s", err)
+	}
+
+	if err := b.ApplyTo(&PaymentParams{Amount: 2000, Currency: "USD"}); err == nil {
+		t.Errorf("Error expected for amount mismatch")
+	}
+
+	invalid := NewPaymentOrderBuilder("order").AddItem(PaymentOrderLineItem{Quantity: 0, UnitPrice: 100})
+	if _, err := invalid.Build(); err == nil {
+		t.Errorf("Error expected for invalid line item")
+	}
+}
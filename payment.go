@@ -16,10 +16,11 @@ type PaymentClient struct {
 // Payment is a model of entity.
 type Payment struct {
 	PaymentParams
+	RawFields
 
 	ID                  string              `json:"id"`
-	Created             json.Number         `json:"created"`
-	Modified            json.Number         `json:"modified"`
+	Created             Timestamp           `json:"created"`
+	Modified            Timestamp           `json:"modified"`
 	Status              PaymentStatus       `json:"status"`
 	PossibleNextActions []PaymentNextAction `json:"possible_next_actions"`
 
@@ -29,6 +30,15 @@ type Payment struct {
 	RelatedResources *PaymentRelatedResources `json:"related_resources"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	type model Payment
+	if err := json.Unmarshal(data, (*model)(p)); err != nil {
+		return err
+	}
+	return p.RawFields.decode(data, p)
+}
+
 // PaymentParams is a set of params for creating and updating entity.
 type PaymentParams struct {
 	Amount                  int64             `json:"amount"`
@@ -41,21 +51,35 @@ type PaymentParams struct {
 	BillingAddress          *Address          `json:"billing_address,omitempty"`
 }
 
-// PaymentOrder represents order description.
+// PaymentOrder represents order description. Order-level amounts and line items with commodity codes are
+// Level 2/3 data, which may qualify for lower interchange fees.
 type PaymentOrder struct {
-	ID                string                 `json:"id,omitempty"`
-	AdditionalDetails AdditionalDetails      `json:"additional_details,omitempty"`
-	TaxAmount         int64                  `json:"tax_amount,omitempty"`
-	TaxPercentage     int64                  `json:"tax_percentage,omitempty"`
-	LineItems         []PaymentOrderLineItem `json:"line_items,omitempty"`
+	ID                string            `json:"id,omitempty"`
+	AdditionalDetails AdditionalDetails `json:"additional_details,omitempty"`
+	// TaxAmount is a total tax of the order. It includes tax amounts of line items and tax which is not split
+	// by line items, e.g. tax of shipping.
+	TaxAmount      int64                  `json:"tax_amount,omitempty"`
+	TaxPercentage  int64                  `json:"tax_percentage,omitempty"`
+	ShippingAmount int64                  `json:"shipping_amount,omitempty"`
+	DutyAmount     int64                  `json:"duty_amount,omitempty"`
+	DiscountAmount int64                  `json:"discount_amount,omitempty"`
+	LineItems      []PaymentOrderLineItem `json:"line_items,omitempty"`
 }
 
 // PaymentOrderLineItem represents one item of order.
 type PaymentOrderLineItem struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Quantity  int64  `json:"quantity,omitempty"`
-	UnitPrice int64  `json:"unit_price,omitempty"`
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name,omitempty"`
+	SKU           string `json:"sku,omitempty"`
+	CommodityCode string `json:"commodity_code,omitempty"`
+	UnitOfMeasure string `json:"unit_of_measure,omitempty"`
+	Quantity      int64  `json:"quantity,omitempty"`
+	UnitPrice     int64  `json:"unit_price,omitempty"`
+	// DiscountAmount is a discount of the whole line, not of a unit.
+	DiscountAmount int64 `json:"discount_amount,omitempty"`
+	TaxAmount      int64 `json:"tax_amount,omitempty"`
+	// TotalAmount is quantity * unit price - discount + tax.
+	TotalAmount int64 `json:"total_amount,omitempty"`
 }
 
 // PaymentNextAction represents action which may be performed on Payment entity.
@@ -72,6 +96,7 @@ type PaymentRelatedResources struct {
 	Redirections   []Redirection   `json:"redirections"`
 	Captures       []Capture       `json:"captures"`
 	Refunds        []Refund        `json:"refunds"`
+	Credits        []Credit        `json:"credits"`
 }
 
 // PaymentStatus is a type of payment status
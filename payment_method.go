@@ -14,11 +14,14 @@ type PaymentMethodClient struct {
 
 // PaymentMethod is a entity model.
 type PaymentMethod struct {
+	RawFields
+
 	Type               string            `json:"type"`
+	SourceType         string            `json:"source_type"`
 	TokenType          string            `json:"token_type"`
 	PassLuhnValidation bool              `json:"pass_luhn_validation"`
 	Token              string            `json:"token"`
-	Created            json.Number       `json:"created"`
+	Created            Timestamp         `json:"created"`
 	Customer           string            `json:"customer"`
 	AdditionalDetails  AdditionalDetails `json:"additional_details"`
 	BinNumber          json.Number       `json:"bin_number"`
@@ -34,6 +37,15 @@ type PaymentMethod struct {
 	BillingAddress     *Address          `json:"billing_address"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *PaymentMethod) UnmarshalJSON(data []byte) error {
+	type model PaymentMethod
+	if err := json.Unmarshal(data, (*model)(p)); err != nil {
+		return err
+	}
+	return p.RawFields.decode(data, p)
+}
+
 // IdentityDocument represents some identity document.
 type IdentityDocument struct {
 	Type   string `json:"type"`
@@ -0,0 +1,325 @@
+package zooz
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// PaymentMethodKind is a kind of payment method.
+type PaymentMethodKind string
+
+// List of supported payment method kinds.
+const (
+	PaymentMethodKindCard         PaymentMethodKind = "card"
+	PaymentMethodKindApplePay     PaymentMethodKind = "apple_pay"
+	PaymentMethodKindGooglePay    PaymentMethodKind = "google_pay"
+	PaymentMethodKindNetworkToken PaymentMethodKind = "network_token"
+	PaymentMethodKindCashVoucher  PaymentMethodKind = "cash"
+	PaymentMethodKindBankTransfer PaymentMethodKind = "bank_transfer"
+)
+
+// Payment method types and source types of wire format.
+const (
+	PaymentMethodTypeTokenized   = "tokenized"
+	PaymentMethodTypeUntokenized = "untokenized"
+
+	SourceTypeApplePay     = "apple_pay"
+	SourceTypeGooglePay    = "google_pay"
+	SourceTypeNetworkToken = "network_token"
+	SourceTypeCash         = "cash"
+	SourceTypeBankTransfer = "bank_transfer"
+)
+
+// PaymentMethodSource is implemented by typed payment methods. Use NewPaymentMethodDetails to get wire format of
+// typed payment method for AuthorizationParams and ChargeParams.
+type PaymentMethodSource interface {
+	Kind() PaymentMethodKind
+	Validate() error
+	PaymentMethodDetails() PaymentMethodDetails
+}
+
+// NewPaymentMethodDetails validates typed payment method and returns its wire format.
+func NewPaymentMethodDetails(source PaymentMethodSource) (PaymentMethodDetails, error) {
+	if err := source.Validate(); err != nil {
+		return PaymentMethodDetails{}, err
+	}
+	return source.PaymentMethodDetails(), nil
+}
+
+// TokenizedCard is a card tokenized by PaymentsOS.
+type TokenizedCard struct {
+	Token string
+	CVV   string
+}
+
+// NewTokenizedCard creates tokenized card payment method. CVV is optional.
+func NewTokenizedCard(token, cvv string) *TokenizedCard {
+	return &TokenizedCard{Token: token, CVV: cvv}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (c *TokenizedCard) Kind() PaymentMethodKind {
+	return PaymentMethodKindCard
+}
+
+// Validate implements PaymentMethodSource interface.
+func (c *TokenizedCard) Validate() error {
+	var v validation
+	if c.Token == "" {
+		v.add("token", "is required")
+	}
+	if c.CVV != "" && !cvvRegexp.MatchString(c.CVV) {
+		v.add("credit_card_cvv", "must be 3 or 4 digits")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (c *TokenizedCard) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeTokenized, Token: c.Token, CreditCardCvv: c.CVV}
+}
+
+// ApplePay is an Apple Pay payment token.
+type ApplePay struct {
+	// PaymentData is paymentData of PKPaymentToken, encrypted by Apple.
+	PaymentData           json.RawMessage `json:"payment_data"`
+	TransactionIdentifier string          `json:"transaction_identifier,omitempty"`
+	PaymentNetwork        string          `json:"payment_network,omitempty"`
+	DisplayName           string          `json:"display_name,omitempty"`
+}
+
+// NewApplePay creates Apple Pay payment method from PKPaymentToken fields.
+func NewApplePay(paymentData json.RawMessage, transactionIdentifier, paymentNetwork string) *ApplePay {
+	return &ApplePay{PaymentData: paymentData, TransactionIdentifier: transactionIdentifier, PaymentNetwork: paymentNetwork}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (a *ApplePay) Kind() PaymentMethodKind {
+	return PaymentMethodKindApplePay
+}
+
+// Validate implements PaymentMethodSource interface.
+func (a *ApplePay) Validate() error {
+	var v validation
+	if len(a.PaymentData) == 0 {
+		v.add("apple_pay.payment_data", "is required")
+	} else if !json.Valid(a.PaymentData) {
+		v.add("apple_pay.payment_data", "must be JSON")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (a *ApplePay) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeApplePay, ApplePay: a}
+}
+
+// GooglePay is a Google Pay payment token.
+type GooglePay struct {
+	// PaymentToken is paymentMethodData.tokenizationData.token of Google Pay PaymentData.
+	PaymentToken string `json:"payment_token"`
+	CardNetwork  string `json:"card_network,omitempty"`
+	// CardDetails are the last 4 digits of the card.
+	CardDetails string `json:"card_details,omitempty"`
+}
+
+// NewGooglePay creates Google Pay payment method from PaymentData fields.
+func NewGooglePay(paymentToken, cardNetwork, cardDetails string) *GooglePay {
+	return &GooglePay{PaymentToken: paymentToken, CardNetwork: cardNetwork, CardDetails: cardDetails}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (g *GooglePay) Kind() PaymentMethodKind {
+	return PaymentMethodKindGooglePay
+}
+
+// Validate implements PaymentMethodSource interface.
+func (g *GooglePay) Validate() error {
+	var v validation
+	if g.PaymentToken == "" {
+		v.add("google_pay.payment_token", "is required")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (g *GooglePay) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeGooglePay, GooglePay: g}
+}
+
+// NetworkToken is a card network token (Visa Token Service, Mastercard MDES) with transaction cryptogram.
+type NetworkToken struct {
+	Number string `json:"token_number"`
+	// ExpirationDate in MM/YYYY format.
+	ExpirationDate string `json:"expiration_date"`
+	Cryptogram     string `json:"cryptogram,omitempty"`
+	EciFlag        string `json:"eci_flag,omitempty"`
+	HolderName     string `json:"holder_name,omitempty"`
+}
+
+// NewNetworkToken creates network token payment method. Cryptogram is required for customer-initiated transactions.
+func NewNetworkToken(number, expirationDate, cryptogram, eciFlag string) *NetworkToken {
+	return &NetworkToken{Number: number, ExpirationDate: expirationDate, Cryptogram: cryptogram, EciFlag: eciFlag}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (n *NetworkToken) Kind() PaymentMethodKind {
+	return PaymentMethodKindNetworkToken
+}
+
+// Validate implements PaymentMethodSource interface.
+func (n *NetworkToken) Validate() error {
+	var v validation
+	if !tokenNumberRegexp.MatchString(n.Number) {
+		v.add("network_token.token_number", "must be 13-19 digits")
+	}
+	if !expirationDateRegexp.MatchString(n.ExpirationDate) {
+		v.add("network_token.expiration_date", "must be in MM/YYYY format")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (n *NetworkToken) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeNetworkToken, NetworkToken: n}
+}
+
+// CashVoucher is an untokenized cash payment method, e.g. OXXO or Boleto. Customer pays the voucher in a shop,
+// voucher itself is returned by provider in ProviderData.
+type CashVoucher struct {
+	Vendor            string
+	AdditionalDetails AdditionalDetails
+}
+
+// NewCashVoucher creates cash voucher payment method of given vendor.
+func NewCashVoucher(vendor string, additionalDetails AdditionalDetails) *CashVoucher {
+	return &CashVoucher{Vendor: vendor, AdditionalDetails: additionalDetails}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (c *CashVoucher) Kind() PaymentMethodKind {
+	return PaymentMethodKindCashVoucher
+}
+
+// Validate implements PaymentMethodSource interface.
+func (c *CashVoucher) Validate() error {
+	var v validation
+	if c.Vendor == "" {
+		v.add("vendor", "is required")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (c *CashVoucher) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeCash, Vendor: c.Vendor, AdditionalDetails: c.AdditionalDetails}
+}
+
+// BankTransfer is an untokenized bank transfer payment method, e.g. SPEI or PSE.
+type BankTransfer struct {
+	Vendor            string
+	AdditionalDetails AdditionalDetails
+}
+
+// NewBankTransfer creates bank transfer payment method of given vendor.
+func NewBankTransfer(vendor string, additionalDetails AdditionalDetails) *BankTransfer {
+	return &BankTransfer{Vendor: vendor, AdditionalDetails: additionalDetails}
+}
+
+// Kind implements PaymentMethodSource interface.
+func (b *BankTransfer) Kind() PaymentMethodKind {
+	return PaymentMethodKindBankTransfer
+}
+
+// Validate implements PaymentMethodSource interface.
+func (b *BankTransfer) Validate() error {
+	var v validation
+	if b.Vendor == "" {
+		v.add("vendor", "is required")
+	}
+	return v.err()
+}
+
+// PaymentMethodDetails implements PaymentMethodSource interface.
+func (b *BankTransfer) PaymentMethodDetails() PaymentMethodDetails {
+	return PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeBankTransfer, Vendor: b.Vendor, AdditionalDetails: b.AdditionalDetails}
+}
+
+var (
+	cvvRegexp            = regexp.MustCompile(`^[0-9]{3,4}$`)
+	tokenNumberRegexp    = regexp.MustCompile(`^[0-9]{13,19}$`)
+	expirationDateRegexp = regexp.MustCompile(`^(0[1-9]|1[0-2])/[0-9]{4}$`)
+)
+
+// hasTypedSource reports whether details are of a kind known to Source. Other alternative payment methods are
+// passed to API as is.
+func (d *PaymentMethodDetails) hasTypedSource() bool {
+	switch d.Type {
+	case PaymentMethodTypeTokenized:
+		return true
+	case PaymentMethodTypeUntokenized:
+		switch d.SourceType {
+		case SourceTypeApplePay, SourceTypeGooglePay, SourceTypeNetworkToken, SourceTypeCash, SourceTypeBankTransfer:
+			return true
+		}
+	}
+	return false
+}
+
+// Source returns typed payment method of request details.
+func (d *PaymentMethodDetails) Source() (PaymentMethodSource, error) {
+	switch d.Type {
+	case PaymentMethodTypeTokenized:
+		return &TokenizedCard{Token: d.Token, CVV: d.CreditCardCvv}, nil
+	case PaymentMethodTypeUntokenized:
+		switch d.SourceType {
+		case SourceTypeApplePay:
+			if d.ApplePay == nil {
+				return nil, errors.New("apple pay details are missing")
+			}
+			return d.ApplePay, nil
+		case SourceTypeGooglePay:
+			if d.GooglePay == nil {
+				return nil, errors.New("google pay details are missing")
+			}
+			return d.GooglePay, nil
+		case SourceTypeNetworkToken:
+			if d.NetworkToken == nil {
+				return nil, errors.New("network token details are missing")
+			}
+			return d.NetworkToken, nil
+		case SourceTypeCash:
+			return &CashVoucher{Vendor: d.Vendor, AdditionalDetails: d.AdditionalDetails}, nil
+		case SourceTypeBankTransfer:
+			return &BankTransfer{Vendor: d.Vendor, AdditionalDetails: d.AdditionalDetails}, nil
+		}
+		return nil, errors.Errorf("unknown source type %q", d.SourceType)
+	}
+	return nil, errors.Errorf("unknown payment method type %q", d.Type)
+}
+
+// Source returns typed payment method of response entity. Sensitive data (wallet payment data, network token
+// number and cryptogram) is never returned by API, so only descriptive fields are filled.
+func (p *PaymentMethod) Source() (PaymentMethodSource, error) {
+	switch p.Type {
+	case PaymentMethodTypeTokenized:
+		return &TokenizedCard{Token: p.Token}, nil
+	case PaymentMethodTypeUntokenized:
+		switch p.SourceType {
+		case SourceTypeApplePay:
+			return &ApplePay{PaymentNetwork: p.Vendor}, nil
+		case SourceTypeGooglePay:
+			return &GooglePay{CardNetwork: p.Vendor, CardDetails: p.Last4Digits}, nil
+		case SourceTypeNetworkToken:
+			return &NetworkToken{ExpirationDate: p.ExpirationDate, HolderName: p.HolderName}, nil
+		case SourceTypeCash:
+			return &CashVoucher{Vendor: p.Vendor, AdditionalDetails: p.AdditionalDetails}, nil
+		case SourceTypeBankTransfer:
+			return &BankTransfer{Vendor: p.Vendor, AdditionalDetails: p.AdditionalDetails}, nil
+		}
+		return nil, errors.Errorf("unknown source type %q", p.SourceType)
+	}
+	return nil, errors.Errorf("unknown payment method type %q", p.Type)
+}
@@ -0,0 +1,152 @@
+package zooz
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewPaymentMethodDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   PaymentMethodSource
+		expected string
+	}{
+		{
+			name:     "tokenized card",
+			source:   NewTokenizedCard("token", "123"),
+			expected: `{"type":"tokenized","token":"token","credit_card_cvv":"123"}`,
+		},
+		{
+			name:     "apple pay",
+			source:   NewApplePay(json.RawMessage(`{"version":"EC_v1","data":"abc"}`), "tx", "Visa"),
+			expected: `{"type":"untokenized","source_type":"apple_pay","apple_pay":{"payment_data":{"version":"EC_v1","data":"abc"},"transaction_identifier":"tx","payment_network":"Visa"}}`,
+		},
+		{
+			name:     "google pay",
+			source:   NewGooglePay(`{"signature":"sig"}`, "VISA", "1111"),
+			expected: `{"type":"untokenized","source_type":"google_pay","google_pay":{"payment_token":"{\"signature\":\"sig\"}","card_network":"VISA","card_details":"1111"}}`,
+		},
+		{
+			name:     "network token",
+			source:   NewNetworkToken("4111111111111111", "12/2030", "cryptogram", "05"),
+			expected: `{"type":"untokenized","source_type":"network_token","network_token":{"token_number":"4111111111111111","expiration_date":"12/2030","cryptogram":"cryptogram","eci_flag":"05"}}`,
+		},
+		{
+			name:     "cash voucher",
+			source:   NewCashVoucher("OXXO", AdditionalDetails{"customer_email": "john@example.com"}),
+			expected: `{"type":"untokenized","source_type":"cash","vendor":"OXXO","additional_details":{"customer_email":"john@example.com"}}`,
+		},
+		{
+			name:     "bank transfer",
+			source:   NewBankTransfer("SPEI", nil),
+			expected: `{"type":"untokenized","source_type":"bank_transfer","vendor":"SPEI"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		details, err := NewPaymentMethodDetails(tt.source)
+		if err != nil {
+			t.Errorf("Error must be nil for %s: %s", tt.name, err)
+			continue
+		}
+		data, err := json.Marshal(details)
+		if err != nil {
+			t.Errorf("Error must be nil for %s: %s", tt.name, err)
+			continue
+		}
+		if string(data) != tt.expected {
+			t.Errorf("JSON of %s is %s, expected %s", tt.name, data, tt.expected)
+		}
+
+		var decoded PaymentMethodDetails
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Errorf("Error must be nil for %s: %s", tt.name, err)
+			continue
+		}
+		source, err := decoded.Source()
+		if err != nil {
+			t.Errorf("Error must be nil for %s: %s", tt.name, err)
+			continue
+		}
+		if source.Kind() != tt.source.Kind() {
+			t.Errorf("Decoded kind of %s is %s", tt.name, source.Kind())
+		}
+	}
+}
+
+func TestPaymentMethodSource_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   PaymentMethodSource
+		expected []string
+	}{
+		{name: "tokenized card", source: NewTokenizedCard("", "12"), expected: []string{"credit_card_cvv", "token"}},
+		{name: "apple pay", source: NewApplePay(json.RawMessage(`not json`), "", ""), expected: []string{"apple_pay.payment_data"}},
+		{name: "google pay", source: NewGooglePay("", "", ""), expected: []string{"google_pay.payment_token"}},
+		{name: "network token", source: NewNetworkToken("4111", "2030-12", "", ""), expected: []string{"network_token.expiration_date", "network_token.token_number"}},
+		{name: "cash voucher", source: NewCashVoucher("", nil), expected: []string{"vendor"}},
+		{name: "bank transfer", source: NewBankTransfer("", nil), expected: []string{"vendor"}},
+	}
+
+	for _, tt := range tests {
+		if fields := validationFields(t, tt.source.Validate()); !equalFields(fields, tt.expected) {
+			t.Errorf("Invalid fields of %s are %v, expected %v", tt.name, fields, tt.expected)
+		}
+		if _, err := NewPaymentMethodDetails(tt.source); err == nil {
+			t.Errorf("Error expected for %s", tt.name)
+		}
+	}
+
+	params := &ChargeParams{PaymentMethod: PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: SourceTypeApplePay}}
+	if fields := validationFields(t, params.Validate()); !equalFields(fields, []string{"payment_method"}) {
+		t.Errorf("Invalid fields of charge params are %v", fields)
+	}
+}
+
+func TestChargeParams_Validate_UnknownSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		paymentMethod PaymentMethodDetails
+		expected      []string
+	}{
+		{name: "empty source type", paymentMethod: PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, Vendor: "PSE"}},
+		{name: "other source type", paymentMethod: PaymentMethodDetails{Type: PaymentMethodTypeUntokenized, SourceType: "ewallet", Vendor: "PayPal"}},
+		{name: "other type", paymentMethod: PaymentMethodDetails{Type: "card_present"}},
+		{name: "missing type", paymentMethod: PaymentMethodDetails{}, expected: []string{"payment_method.type"}},
+		{name: "tokenized without token", paymentMethod: PaymentMethodDetails{Type: PaymentMethodTypeTokenized}, expected: []string{"payment_method.token"}},
+	}
+
+	for _, tt := range tests {
+		params := &ChargeParams{PaymentMethod: tt.paymentMethod}
+		if fields := validationFields(t, params.Validate()); !equalFields(fields, tt.expected) {
+			t.Errorf("Invalid fields of %s are %v, expected %v", tt.name, fields, tt.expected)
+		}
+	}
+}
+
+func TestPaymentMethod_Source(t *testing.T) {
+	var p PaymentMethod
+	data := `{"type":"untokenized","source_type":"cash","vendor":"OXXO","additional_details":{"reference":"123"}}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	source, err := p.Source()
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	voucher, ok := source.(*CashVoucher)
+	if !ok || voucher.Vendor != "OXXO" || voucher.AdditionalDetails["reference"] != "123" {
+		t.Errorf("Source is not as expected: %#v", source)
+	}
+
+	p = PaymentMethod{Type: "tokenized", Token: "token"}
+	if source, err := p.Source(); err != nil || source.(*TokenizedCard).Token != "token" {
+		t.Errorf("Source is not as expected: %#v, %v", source, err)
+	}
+
+	p = PaymentMethod{Type: "untokenized", SourceType: "crypto"}
+	if _, err := p.Source(); err == nil {
+		t.Errorf("Error expected for unknown source type")
+	}
+}
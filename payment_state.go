@@ -0,0 +1,216 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PaymentTransition describes an action which may be performed on payment in some status, and statuses the
+// payment may get after the action.
+type PaymentTransition struct {
+	Action PaymentAction
+	From   PaymentStatus
+	To     []PaymentStatus
+}
+
+// PaymentTransitions is a client-side model of Payment state machine.
+// https://developers.paymentsos.com/docs/payments-flow.html
+var PaymentTransitions = []PaymentTransition{
+	{Action: PaymentActionUpdatePayment, From: PaymentStatusInitialized, To: []PaymentStatus{PaymentStatusInitialized}},
+	{Action: PaymentActionAuthorize, From: PaymentStatusInitialized, To: []PaymentStatus{PaymentStatusAuthorized, PaymentStatusPending, PaymentStatusInitialized}},
+	{Action: PaymentActionCharge, From: PaymentStatusInitialized, To: []PaymentStatus{PaymentStatusCaptured, PaymentStatusPending, PaymentStatusInitialized}},
+	{Action: PaymentActionCapture, From: PaymentStatusAuthorized, To: []PaymentStatus{PaymentStatusCaptured, PaymentStatusPending, PaymentStatusAuthorized}},
+	{Action: PaymentActionVoid, From: PaymentStatusAuthorized, To: []PaymentStatus{PaymentStatusVoided, PaymentStatusAuthorized}},
+	{Action: PaymentActionRefund, From: PaymentStatusCaptured, To: []PaymentStatus{PaymentStatusRefunded, PaymentStatusPending, PaymentStatusCaptured}},
+	{Action: PaymentActionRefund, From: PaymentStatusRefunded, To: []PaymentStatus{PaymentStatusRefunded}},
+}
+
+// paymentStatusTransitions lists statuses payment may move to from each status, including asynchronous
+// transitions without actions (e.g. Pending payment is authorized after redirection).
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusInitialized: {PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured},
+	PaymentStatusPending:     {PaymentStatusInitialized, PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusRefunded, PaymentStatusVoided},
+	PaymentStatusAuthorized:  {PaymentStatusPending, PaymentStatusCaptured, PaymentStatusVoided},
+	PaymentStatusCaptured:    {PaymentStatusPending, PaymentStatusRefunded},
+	PaymentStatusRefunded:    {},
+	PaymentStatusVoided:      {},
+}
+
+// PaymentActionsFor returns actions which may be performed on payment in given status.
+func PaymentActionsFor(status PaymentStatus) []PaymentAction {
+	var actions []PaymentAction
+	for _, transition := range PaymentTransitions {
+		if transition.From == status {
+			actions = append(actions, transition.Action)
+		}
+	}
+	return actions
+}
+
+// Can reports whether action may be performed on payment. Possible next actions returned by API are used if
+// present, otherwise the decision is made by client-side state machine.
+func (p *Payment) Can(action PaymentAction) bool {
+	for _, allowed := range p.allowedActions() {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Payment) allowedActions() []PaymentAction {
+	if len(p.PossibleNextActions) == 0 {
+		return PaymentActionsFor(p.Status)
+	}
+	actions := make([]PaymentAction, 0, len(p.PossibleNextActions))
+	for _, next := range p.PossibleNextActions {
+		actions = append(actions, next.Action)
+	}
+	return actions
+}
+
+// IllegalActionError is returned by guarded actions when action is not allowed for payment.
+type IllegalActionError struct {
+	PaymentID string
+	Status    PaymentStatus
+	Action    PaymentAction
+	Allowed   []PaymentAction
+}
+
+// Error implements error interface.
+func (e *IllegalActionError) Error() string {
+	allowed := make([]string, 0, len(e.Allowed))
+	for _, action := range e.Allowed {
+		allowed = append(allowed, string(action))
+	}
+	return fmt.Sprintf("action %q is not allowed for payment %s in status %q, allowed actions: [%s]", e.Action, e.PaymentID, e.Status, strings.Join(allowed, ", "))
+}
+
+// CheckAction returns *IllegalActionError if action may not be performed on payment.
+func (p *Payment) CheckAction(action PaymentAction) error {
+	if p.Can(action) {
+		return nil
+	}
+	return &IllegalActionError{
+		PaymentID: p.ID,
+		Status:    p.Status,
+		Action:    action,
+		Allowed:   p.allowedActions(),
+	}
+}
+
+// InvalidTransitionError is returned by transition validators when payment can't move between statuses.
+type InvalidTransitionError struct {
+	From PaymentStatus
+	To   PaymentStatus
+}
+
+// Error implements error interface.
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("payment can't move from status %q to status %q", e.From, e.To)
+}
+
+// ValidatePaymentTransition checks whether payment may move from one status to another.
+// Staying in the same status is always valid, because the same notification may be delivered several times.
+func ValidatePaymentTransition(from, to PaymentStatus) error {
+	if from == to {
+		return nil
+	}
+	for _, status := range paymentStatusTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+	return &InvalidTransitionError{From: from, To: to}
+}
+
+// ValidatePaymentStatuses checks sequence of payment statuses, e.g. received with webhooks, and returns error
+// for the first invalid transition.
+func ValidatePaymentStatuses(statuses ...PaymentStatus) error {
+	for i := 1; i < len(statuses); i++ {
+		if err := ValidatePaymentTransition(statuses[i-1], statuses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GuardedClient performs payment actions only if they are allowed for the payment, otherwise it returns
+// *IllegalActionError without making a network call. Capture and refund amounts are checked against PaymentLedger
+// of the payment requested with all expansions, and *BalanceError is returned for over-capture or over-refund.
+type GuardedClient struct {
+	API API
+}
+
+// NewGuardedClient creates GuardedClient on top of given API.
+func NewGuardedClient(api API) *GuardedClient {
+	return &GuardedClient{API: api}
+}
+
+// Capture creates new Capture entity for given payment.
+func (g *GuardedClient) Capture(ctx context.Context, idempotencyKey string, payment *Payment, params *CaptureParams) (*Capture, error) {
+	if err := payment.CheckAction(PaymentActionCapture); err != nil {
+		return nil, err
+	}
+	ledger, err := g.ledger(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	var amount int64
+	if params != nil {
+		amount = params.Amount
+	}
+	if err := ledger.CheckCapture(amount); err != nil {
+		return nil, err
+	}
+	return g.API.Capture().New(ctx, idempotencyKey, payment.ID, params)
+}
+
+// Void creates new Void entity for given payment.
+func (g *GuardedClient) Void(ctx context.Context, idempotencyKey string, payment *Payment) (*Void, error) {
+	if err := payment.CheckAction(PaymentActionVoid); err != nil {
+		return nil, err
+	}
+	return g.API.Void().New(ctx, idempotencyKey, payment.ID)
+}
+
+// Refund creates new Refund entity for given payment.
+func (g *GuardedClient) Refund(ctx context.Context, idempotencyKey string, payment *Payment, params *RefundParams) (*Refund, error) {
+	if err := payment.CheckAction(PaymentActionRefund); err != nil {
+		return nil, err
+	}
+	ledger, err := g.ledger(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	var amount int64
+	var captureID string
+	if params != nil {
+		amount, captureID = params.Amount, params.CaptureID
+	}
+	if err := ledger.CheckRefund(amount, captureID); err != nil {
+		return nil, err
+	}
+	return g.API.Refund().New(ctx, idempotencyKey, payment.ID, params)
+}
+
+// ledger requests payment with all expansions and calculates its ledger. Related resources of given payment
+// are not used, they may be requested without some expansions.
+func (g *GuardedClient) ledger(ctx context.Context, payment *Payment) (*PaymentLedger, error) {
+	full, err := g.API.Payment().Get(ctx, payment.ID, PaymentExpandAll)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get payment %s", payment.ID)
+	}
+	return NewPaymentLedger(full)
+}
+
+// Update changes given payment and returns updated entity.
+func (g *GuardedClient) Update(ctx context.Context, payment *Payment, params *PaymentParams) (*Payment, error) {
+	if err := payment.CheckAction(PaymentActionUpdatePayment); err != nil {
+		return nil, err
+	}
+	return g.API.Payment().Update(ctx, payment.ID, params)
+}
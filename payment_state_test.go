@@ -0,0 +1,103 @@
+package zooz
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPayment_Can(t *testing.T) {
+	authorized := &Payment{ID: "id", Status: PaymentStatusAuthorized}
+	if !authorized.Can(PaymentActionCapture) || !authorized.Can(PaymentActionVoid) {
+		t.Error("Authorized payment must allow capture and void")
+	}
+	if authorized.Can(PaymentActionRefund) || authorized.Can(PaymentActionAuthorize) {
+		t.Error("Authorized payment must not allow refund and authorize")
+	}
+
+	// Possible next actions returned by API take precedence over client-side state machine.
+	withNextActions := &Payment{
+		ID:                  "id",
+		Status:              PaymentStatusAuthorized,
+		PossibleNextActions: []PaymentNextAction{{Action: PaymentActionVoid}},
+	}
+	if withNextActions.Can(PaymentActionCapture) {
+		t.Error("Payment must not allow action missing in possible next actions")
+	}
+	if !withNextActions.Can(PaymentActionVoid) {
+		t.Error("Payment must allow action from possible next actions")
+	}
+
+	voided := &Payment{ID: "id", Status: PaymentStatusVoided}
+	if len(PaymentActionsFor(voided.Status)) != 0 {
+		t.Errorf("Voided payment must not allow any action: %v", PaymentActionsFor(voided.Status))
+	}
+}
+
+func TestPayment_CheckAction(t *testing.T) {
+	payment := &Payment{ID: "id", Status: PaymentStatusCaptured}
+
+	err := payment.CheckAction(PaymentActionVoid)
+	illegalErr, ok := err.(*IllegalActionError)
+	if !ok {
+		t.Fatalf("Invalid error type: %T", err)
+	}
+	if illegalErr.Action != PaymentActionVoid || illegalErr.Status != PaymentStatusCaptured {
+		t.Errorf("Invalid error: %+v", illegalErr)
+	}
+	if err.Error() != `action "Void" is not allowed for payment id in status "Captured", allowed actions: [Refund]` {
+		t.Errorf("Invalid error message: %s", err)
+	}
+}
+
+func TestGuardedClient(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.Get", "id", []PaymentExpand{PaymentExpandAll}).Return(&Payment{ID: "id", RelatedResources: &PaymentRelatedResources{
+		Authorizations: []Authorization{{Amount: 100, Result: Result{Status: ResultStatusSucceed}}},
+	}}, nil).Once()
+	mock.On("Capture.New", "key", "id", &CaptureParams{Amount: 100}).Return(&Capture{ID: "capture_id"}, nil).Once()
+
+	g := NewGuardedClient(mock)
+	payment := &Payment{ID: "id", Status: PaymentStatusAuthorized}
+
+	capture, err := g.Capture(context.Background(), "key", payment, &CaptureParams{Amount: 100})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if capture.ID != "capture_id" {
+		t.Errorf("Capture is not as expected: %+v", capture)
+	}
+
+	if _, err := g.Refund(context.Background(), "key", payment, &RefundParams{}); err == nil {
+		t.Error("Refund of authorized payment must fail")
+	}
+	if _, err := g.Update(context.Background(), payment, &PaymentParams{}); err == nil {
+		t.Error("Update of authorized payment must fail")
+	}
+
+	mock.AssertExpectations(t)
+	if len(mock.Calls()) != 2 {
+		t.Errorf("Illegal actions must not make calls: %+v", mock.Calls())
+	}
+}
+
+func TestValidatePaymentStatuses(t *testing.T) {
+	if err := ValidatePaymentStatuses(
+		PaymentStatusInitialized,
+		PaymentStatusPending,
+		PaymentStatusAuthorized,
+		PaymentStatusAuthorized,
+		PaymentStatusCaptured,
+		PaymentStatusRefunded,
+	); err != nil {
+		t.Errorf("Sequence must be valid: %s", err)
+	}
+
+	err := ValidatePaymentStatuses(PaymentStatusInitialized, PaymentStatusAuthorized, PaymentStatusVoided, PaymentStatusCaptured)
+	transitionErr, ok := err.(*InvalidTransitionError)
+	if !ok {
+		t.Fatalf("Invalid error type: %T", err)
+	}
+	if transitionErr.From != PaymentStatusVoided || transitionErr.To != PaymentStatusCaptured {
+		t.Errorf("Invalid error: %+v", transitionErr)
+	}
+}
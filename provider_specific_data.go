@@ -0,0 +1,172 @@
+package zooz
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ProviderSpecificDataAdapter converts provider specific data of one provider between typed value and
+// map[string]interface{} used in requests and responses.
+type ProviderSpecificDataAdapter interface {
+	// ProviderName returns name of provider as in ProviderData.ProviderName.
+	ProviderName() string
+	// Encode converts typed value to provider specific data.
+	Encode(value interface{}) (map[string]interface{}, error)
+	// Decode converts provider specific data to typed value.
+	Decode(data map[string]interface{}) (interface{}, error)
+}
+
+// structAdapter is a ProviderSpecificDataAdapter converting data to struct of given type through JSON.
+type structAdapter struct {
+	providerName string
+	typ          reflect.Type
+}
+
+// NewProviderSpecificDataAdapter returns adapter converting provider specific data through JSON to values of
+// the same type as prototype. Prototype must be a struct or pointer to struct, e.g. MyProviderData{}.
+// Decode returns pointer to new value, Encode accepts both value and pointer.
+func NewProviderSpecificDataAdapter(providerName string, prototype interface{}) (ProviderSpecificDataAdapter, error) {
+	typ := reflect.TypeOf(prototype)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.Errorf("prototype of provider specific data of %s must be a struct, got %T", providerName, prototype)
+	}
+	return &structAdapter{providerName: providerName, typ: typ}, nil
+}
+
+// ProviderName implements ProviderSpecificDataAdapter interface.
+func (a *structAdapter) ProviderName() string {
+	return a.providerName
+}
+
+// Encode implements ProviderSpecificDataAdapter interface.
+func (a *structAdapter) Encode(value interface{}) (map[string]interface{}, error) {
+	typ := reflect.TypeOf(value)
+	if typ != a.typ && typ != reflect.PtrTo(a.typ) {
+		return nil, errors.Errorf("invalid provider specific data of %s: expected %s, got %T", a.providerName, a.typ, value)
+	}
+	return encodeProviderSpecificData(value)
+}
+
+// Decode implements ProviderSpecificDataAdapter interface.
+func (a *structAdapter) Decode(data map[string]interface{}) (interface{}, error) {
+	value := reflect.New(a.typ).Interface()
+	if err := decodeProviderSpecificData(data, value); err != nil {
+		return nil, errors.Wrapf(err, "invalid provider specific data of %s", a.providerName)
+	}
+	return value, nil
+}
+
+// ProviderSpecificDataRegistry keeps provider specific data adapters by provider name.
+// Data of providers without adapter is kept as raw map. It is safe for concurrent use.
+type ProviderSpecificDataRegistry struct {
+	mu       sync.RWMutex
+	adapters map[string]ProviderSpecificDataAdapter
+}
+
+// NewProviderSpecificDataRegistry returns registry with given adapters.
+func NewProviderSpecificDataRegistry(adapters ...ProviderSpecificDataAdapter) *ProviderSpecificDataRegistry {
+	r := &ProviderSpecificDataRegistry{adapters: map[string]ProviderSpecificDataAdapter{}}
+	for _, adapter := range adapters {
+		r.Register(adapter)
+	}
+	return r
+}
+
+// DefaultProviderSpecificDataRegistry is used by ProviderSpecific methods of Authorization and Charge.
+var DefaultProviderSpecificDataRegistry = NewProviderSpecificDataRegistry()
+
+// RegisterProviderSpecificDataAdapter adds adapter to DefaultProviderSpecificDataRegistry.
+func RegisterProviderSpecificDataAdapter(adapter ProviderSpecificDataAdapter) {
+	DefaultProviderSpecificDataRegistry.Register(adapter)
+}
+
+// Register adds adapter, replacing previous adapter of the same provider.
+func (r *ProviderSpecificDataRegistry) Register(adapter ProviderSpecificDataAdapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters[adapter.ProviderName()] = adapter
+}
+
+// Adapter returns adapter of provider.
+func (r *ProviderSpecificDataRegistry) Adapter(providerName string) (ProviderSpecificDataAdapter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	adapter, ok := r.adapters[providerName]
+	return adapter, ok
+}
+
+// Encode converts typed value to provider specific data for request params. Raw map is returned as is,
+// values of providers without adapter are converted through JSON.
+func (r *ProviderSpecificDataRegistry) Encode(providerName string, value interface{}) (map[string]interface{}, error) {
+	if data, ok := value.(map[string]interface{}); ok {
+		return data, nil
+	}
+	if adapter, ok := r.Adapter(providerName); ok {
+		return adapter.Encode(value)
+	}
+	return encodeProviderSpecificData(value)
+}
+
+// Decode converts provider specific data to typed value. Data of providers without adapter is returned as raw map.
+func (r *ProviderSpecificDataRegistry) Decode(providerName string, data map[string]interface{}) (interface{}, error) {
+	if adapter, ok := r.Adapter(providerName); ok {
+		return adapter.Decode(data)
+	}
+	return data, nil
+}
+
+// ProviderSpecific returns provider specific data of authorization decoded by DefaultProviderSpecificDataRegistry.
+func (a *Authorization) ProviderSpecific() (interface{}, error) {
+	return DefaultProviderSpecificDataRegistry.Decode(a.ProviderData.ProviderName, a.ProviderSpecificData)
+}
+
+// ProviderSpecific returns provider specific data of charge decoded by DefaultProviderSpecificDataRegistry.
+func (c *Charge) ProviderSpecific() (interface{}, error) {
+	return DefaultProviderSpecificDataRegistry.Decode(c.ProviderData.ProviderName, c.ProviderSpecificData)
+}
+
+// SetProviderSpecific sets provider specific data encoded by DefaultProviderSpecificDataRegistry.
+func (p *AuthorizationParams) SetProviderSpecific(providerName string, value interface{}) error {
+	data, err := DefaultProviderSpecificDataRegistry.Encode(providerName, value)
+	if err != nil {
+		return err
+	}
+	p.ProviderSpecificData = data
+	return nil
+}
+
+// SetProviderSpecific sets provider specific data encoded by DefaultProviderSpecificDataRegistry.
+func (p *ChargeParams) SetProviderSpecific(providerName string, value interface{}) error {
+	data, err := DefaultProviderSpecificDataRegistry.Encode(providerName, value)
+	if err != nil {
+		return err
+	}
+	p.ProviderSpecificData = data
+	return nil
+}
+
+func encodeProviderSpecificData(value interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal provider specific data")
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, errors.Wrap(err, "provider specific data must be a JSON object")
+	}
+	return data, nil
+}
+
+func decodeProviderSpecificData(data map[string]interface{}, value interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal provider specific data")
+	}
+	return errors.Wrap(json.Unmarshal(raw, value), "failed to unmarshal provider specific data")
+}
@@ -0,0 +1,98 @@
+package zooz
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testProviderData struct {
+	MerchantCategory string            `json:"merchant_category"`
+	Descriptor       string            `json:"descriptor,omitempty"`
+	Extra            map[string]string `json:"extra,omitempty"`
+}
+
+func TestProviderSpecificDataRegistry(t *testing.T) {
+	adapter, err := NewProviderSpecificDataAdapter("TestProvider", testProviderData{})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	registry := NewProviderSpecificDataRegistry(adapter)
+
+	data, err := registry.Encode("TestProvider", &testProviderData{MerchantCategory: "5411", Extra: map[string]string{"a": "b"}})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	expected := map[string]interface{}{"merchant_category": "5411", "extra": map[string]interface{}{"a": "b"}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Data is %v, expected %v", data, expected)
+	}
+
+	value, err := registry.Decode("TestProvider", data)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	typed, ok := value.(*testProviderData)
+	if !ok || typed.MerchantCategory != "5411" || typed.Extra["a"] != "b" {
+		t.Errorf("Value is not as expected: %#v", value)
+	}
+
+	if _, err := registry.Encode("TestProvider", struct{ Other string }{}); err == nil {
+		t.Error("Error expected for value of invalid type")
+	}
+	if _, err := registry.Decode("TestProvider", map[string]interface{}{"merchant_category": 1}); err == nil {
+		t.Error("Error expected for data of invalid type")
+	}
+
+	raw := map[string]interface{}{"key": "value"}
+	if value, err := registry.Decode("Unknown", raw); err != nil || !reflect.DeepEqual(value, raw) {
+		t.Errorf("Raw map expected for unknown provider: %v, %v", value, err)
+	}
+	if data, err := registry.Encode("TestProvider", raw); err != nil || !reflect.DeepEqual(data, raw) {
+		t.Errorf("Raw map must be encoded as is: %v, %v", data, err)
+	}
+	if data, err := registry.Encode("Unknown", testProviderData{MerchantCategory: "5411"}); err != nil || data["merchant_category"] != "5411" {
+		t.Errorf("Data is not as expected for unknown provider: %v, %v", data, err)
+	}
+}
+
+func TestCharge_ProviderSpecific(t *testing.T) {
+	adapter, err := NewProviderSpecificDataAdapter("TestProvider", &testProviderData{})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	RegisterProviderSpecificDataAdapter(adapter)
+	defer func() {
+		DefaultProviderSpecificDataRegistry = NewProviderSpecificDataRegistry()
+	}()
+
+	params := &ChargeParams{}
+	if err := params.SetProviderSpecific("TestProvider", testProviderData{MerchantCategory: "5411"}); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if params.ProviderSpecificData["merchant_category"] != "5411" {
+		t.Errorf("Provider specific data is not as expected: %v", params.ProviderSpecificData)
+	}
+
+	var charge Charge
+	data := `{"id":"charge","provider_data":{"provider_name":"TestProvider"},"provider_specific_data":{"merchant_category":"5411"}}`
+	if err := json.Unmarshal([]byte(data), &charge); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	value, err := charge.ProviderSpecific()
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if typed, ok := value.(*testProviderData); !ok || typed.MerchantCategory != "5411" {
+		t.Errorf("Value is not as expected: %#v", value)
+	}
+}
+
+func TestNewProviderSpecificDataAdapter(t *testing.T) {
+	if _, err := NewProviderSpecificDataAdapter("TestProvider", map[string]string{}); err == nil {
+		t.Error("Error expected for prototype which is not a struct")
+	}
+	if _, err := NewProviderSpecificDataAdapter("TestProvider", nil); err == nil {
+		t.Error("Error expected for nil prototype")
+	}
+}
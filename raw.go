@@ -0,0 +1,139 @@
+package zooz
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RawFields keeps raw JSON of decoded entity and its top-level fields which are not described by the model.
+// It is embedded into every response entity, so fields recently added to API are reachable before the client
+// is updated.
+type RawFields struct {
+	raw     json.RawMessage
+	unknown map[string]json.RawMessage
+}
+
+// RawJSON returns JSON the entity was decoded from. It is nil for entities which were not decoded from JSON.
+func (r RawFields) RawJSON() json.RawMessage {
+	return r.raw
+}
+
+// UnknownFields returns top-level JSON fields of the entity which are not described by the model.
+func (r RawFields) UnknownFields() map[string]json.RawMessage {
+	return r.unknown
+}
+
+// UnknownFieldsHook is called by Client in strict decoding mode when API response contains fields which are not
+// described by models. Fields are dot-separated JSON paths, e.g. "related_resources.captures[].new_field".
+type UnknownFieldsHook func(method, path string, fields []string)
+
+// OptUnknownFieldsHook returns option which enables strict decoding mode: every response is checked for fields
+// unknown to models, and found fields are reported to given hook. Decoding itself is not affected.
+func OptUnknownFieldsHook(hook UnknownFieldsHook) Option {
+	return func(c *Client) {
+		c.unknownFieldsHook = hook
+	}
+}
+
+// decode fills raw JSON and unknown fields of entity of given type.
+func (r *RawFields) decode(data []byte, entity interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	r.raw = append(json.RawMessage(nil), data...)
+	r.unknown = nil
+
+	known := jsonFields(reflect.TypeOf(entity).Elem())
+	for name, value := range fields {
+		if _, ok := known[name]; ok {
+			continue
+		}
+		if r.unknown == nil {
+			r.unknown = map[string]json.RawMessage{}
+		}
+		r.unknown[name] = value
+	}
+	return nil
+}
+
+// unknownJSONFields returns sorted paths of JSON object keys which have no corresponding field in given type.
+func unknownJSONFields(data []byte, typ reflect.Type) ([]string, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var fields []string
+	for _, field := range collectUnknownJSONFields("", value, typ) {
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func collectUnknownJSONFields(path string, data interface{}, typ reflect.Type) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var unknown []string
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+		fields := jsonFields(typ)
+		for key, item := range value {
+			fieldType, ok := fields[key]
+			if !ok {
+				unknown = append(unknown, path+key)
+				continue
+			}
+			unknown = append(unknown, collectUnknownJSONFields(path+key+".", item, fieldType)...)
+		}
+	case []interface{}:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			return nil
+		}
+		for _, item := range value {
+			unknown = append(unknown, collectUnknownJSONFields(strings.TrimSuffix(path, ".")+"[].", item, typ.Elem())...)
+		}
+	}
+	return unknown
+}
+
+// jsonFields returns JSON names of struct fields including fields of embedded structs.
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range jsonFields(field.Type) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embeddedType
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
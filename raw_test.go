@@ -0,0 +1,107 @@
+package zooz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRawFields_Entity(t *testing.T) {
+	data := `{"id":"id","amount":100,"new_field":{"a":1},"related_resources":{"captures":[{"id":"capture_id","new_capture_field":"x"}]}}`
+
+	var payment Payment
+	if err := json.Unmarshal([]byte(data), &payment); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+
+	if payment.ID != "id" || payment.Amount != 100 {
+		t.Errorf("Payment is not as expected: %+v", payment)
+	}
+	if string(payment.RawJSON()) != data {
+		t.Errorf("Invalid raw JSON: %s", payment.RawJSON())
+	}
+	if len(payment.UnknownFields()) != 1 || string(payment.UnknownFields()["new_field"]) != `{"a":1}` {
+		t.Errorf("Invalid unknown fields: %s", payment.UnknownFields())
+	}
+
+	capture := payment.RelatedResources.Captures[0]
+	if capture.ID != "capture_id" {
+		t.Errorf("Capture is not as expected: %+v", capture)
+	}
+	if string(capture.UnknownFields()["new_capture_field"]) != `"x"` {
+		t.Errorf("Invalid capture unknown fields: %s", capture.UnknownFields())
+	}
+}
+
+func TestRawFields_NoUnknownFields(t *testing.T) {
+	var refund Refund
+	if err := json.Unmarshal([]byte(`{"id":"id","amount":100,"capture_id":"capture_id"}`), &refund); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+	if refund.Amount != 100 || refund.CaptureID != "capture_id" {
+		t.Errorf("Refund is not as expected: %+v", refund)
+	}
+	if refund.UnknownFields() != nil {
+		t.Errorf("Unknown fields must be nil: %s", refund.UnknownFields())
+	}
+
+	encoded, err := json.Marshal(&refund)
+	if err != nil {
+		t.Fatalf("Marshal error: %s", err)
+	}
+	if bytes.Contains(encoded, []byte("RawFields")) || bytes.Contains(encoded, []byte("unknown")) {
+		t.Errorf("Raw fields must not be encoded: %s", encoded)
+	}
+}
+
+func TestUnknownJSONFields(t *testing.T) {
+	data := []byte(`{"id":"id","unknown":1,"result":{"status":"Succeed","extra":true},"provider_data":{"documents":[{"href":"h","size":1},{"size":2}]}}`)
+
+	fields, err := unknownJSONFields(data, reflect.TypeOf(&Authorization{}))
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	expected := []string{"provider_data.documents[].size", "result.extra", "unknown"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Invalid unknown fields: %q", fields)
+	}
+}
+
+func TestCall_WithUnknownFieldsHook(t *testing.T) {
+	httpClientMock := &httpClientMock{
+		do: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`[{"id":"id","created":"1","new_field":1}]`)),
+			}, nil
+		},
+	}
+
+	var hookMethod, hookPath string
+	var hookFields []string
+	client := New(
+		OptHTTPClient(httpClientMock),
+		OptUnknownFieldsHook(func(method, path string, fields []string) {
+			hookMethod, hookPath, hookFields = method, path, fields
+		}),
+	)
+
+	refunds, err := client.Refund().GetList(context.Background(), "payment_id")
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if len(refunds) != 1 || refunds[0].ID != "id" {
+		t.Errorf("Refunds are not as expected: %+v", refunds)
+	}
+	if hookMethod != "GET" || hookPath != "payments/payment_id/refunds" {
+		t.Errorf("Invalid hook call: %s %s", hookMethod, hookPath)
+	}
+	if !reflect.DeepEqual(hookFields, []string{"[].new_field"}) {
+		t.Errorf("Invalid hook fields: %q", hookFields)
+	}
+}
@@ -14,10 +14,21 @@ type RedirectionClient struct {
 
 // Redirection is a entity model.
 type Redirection struct {
-	ID              string      `json:"id"`
-	Created         json.Number `json:"created"`
-	MerchantSiteURL string      `json:"merchant_site_url"`
-	URL             string      `json:"url"`
+	RawFields
+
+	ID              string    `json:"id"`
+	Created         Timestamp `json:"created"`
+	MerchantSiteURL string    `json:"merchant_site_url"`
+	URL             string    `json:"url"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (r *Redirection) UnmarshalJSON(data []byte) error {
+	type model Redirection
+	if err := json.Unmarshal(data, (*model)(r)); err != nil {
+		return err
+	}
+	return r.RawFields.decode(data, r)
 }
 
 // Get creates new Redirection entity.
@@ -0,0 +1,232 @@
+package zooz
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Query params of signed return URLs. They are prefixed, so params added by providers don't clash with them.
+const (
+	returnParamPaymentID = "zooz_payment_id"
+	returnParamState     = "zooz_state"
+	returnParamExpires   = "zooz_expires"
+	returnParamSignature = "zooz_signature"
+)
+
+// ReturnState is a merchant state carried by signed return URL.
+type ReturnState struct {
+	PaymentID string
+	// State is any merchant data, e.g. order ID. It is not encrypted, only protected from tampering.
+	State     string
+	ExpiresAt time.Time
+}
+
+// ReturnURLSigner builds and verifies tamper-proof merchant site URLs, the customer is sent back to after
+// redirection to 3-D Secure page or alternative payment method.
+type ReturnURLSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// MinReturnURLKeyLength is the minimal length of ReturnURLSigner secret key in bytes.
+const MinReturnURLKeyLength = 32
+
+// NewReturnURLSigner creates signer with given secret key of at least MinReturnURLKeyLength random bytes.
+// Signed URLs expire after ttl, zero ttl means URLs never expire.
+func NewReturnURLSigner(key []byte, ttl time.Duration) (*ReturnURLSigner, error) {
+	if len(key) < MinReturnURLKeyLength {
+		return nil, errors.Errorf("return URL signer key must be at least %d bytes, got %d", MinReturnURLKeyLength, len(key))
+	}
+	return &ReturnURLSigner{key: key, ttl: ttl, now: time.Now}, nil
+}
+
+// Sign returns merchantSiteURL with payment ID, state and signature added to the query.
+// Use the result as MerchantSiteURL of AuthorizationParams or ChargeParams.
+func (s *ReturnURLSigner) Sign(merchantSiteURL string, paymentID string, state string) (string, error) {
+	u, err := url.Parse(merchantSiteURL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse merchant site URL")
+	}
+
+	var expires string
+	if s.ttl > 0 {
+		expires = strconv.FormatInt(s.now().Add(s.ttl).Unix(), 10)
+	}
+
+	query := u.Query()
+	query.Set(returnParamPaymentID, paymentID)
+	query.Set(returnParamState, state)
+	if expires != "" {
+		query.Set(returnParamExpires, expires)
+	}
+	query.Set(returnParamSignature, s.signature(u.Path, paymentID, state, expires))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Verify checks signature and expiration of return URL and returns state it carries.
+func (s *ReturnURLSigner) Verify(u *url.URL) (*ReturnState, error) {
+	query := u.Query()
+	paymentID := query.Get(returnParamPaymentID)
+	state := query.Get(returnParamState)
+	expires := query.Get(returnParamExpires)
+
+	if paymentID == "" {
+		return nil, errors.New("return URL has no payment ID")
+	}
+
+	signature := s.signature(u.Path, paymentID, state, expires)
+	if !hmac.Equal([]byte(signature), []byte(query.Get(returnParamSignature))) {
+		return nil, errors.New("return URL signature is invalid")
+	}
+
+	result := &ReturnState{PaymentID: paymentID, State: state}
+	if expires != "" {
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "return URL expiration is invalid")
+		}
+		result.ExpiresAt = time.Unix(unix, 0)
+		if s.now().After(result.ExpiresAt) {
+			return nil, errors.Errorf("return URL expired at %s", result.ExpiresAt)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *ReturnURLSigner) signature(path, paymentID, state, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	// Canonical URL encoding is unambiguous, so bytes can't be moved between fields without changing signature.
+	mac.Write([]byte(url.Values{
+		"path":       {path},
+		"payment_id": {paymentID},
+		"state":      {state},
+		"expires":    {expires},
+	}.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// RedirectionOutcomeStatus is a type of final outcome of redirection flow.
+type RedirectionOutcomeStatus string
+
+// List of possible redirection outcome statuses.
+const (
+	RedirectionOutcomeApproved RedirectionOutcomeStatus = "approved"
+	RedirectionOutcomeDeclined RedirectionOutcomeStatus = "declined"
+	RedirectionOutcomePending  RedirectionOutcomeStatus = "pending"
+)
+
+// RedirectionOutcome is a result of redirection flow, passed to RedirectionHandler callback.
+type RedirectionOutcome struct {
+	Status RedirectionOutcomeStatus
+	ReturnState
+	// Payment is expanded with authorizations, charges and redirections.
+	Payment *Payment
+	// Authorization or Charge is the latest transaction of the payment.
+	Authorization *Authorization
+	Charge        *Charge
+	// Redirection is the latest redirection of the payment.
+	Redirection *Redirection
+}
+
+// RedirectionHandler handles customer return to merchant site after redirection. It verifies return URL,
+// gets payment with its authorizations, charges and redirections, and calls OnOutcome callback.
+type RedirectionHandler struct {
+	Payments  PaymentAPI
+	Signer *ReturnURLSigner
+	// OnOutcome responds to customer. By default it responds with 200 status and outcome status in the body.
+	OnOutcome func(w http.ResponseWriter, r *http.Request, outcome *RedirectionOutcome)
+	// OnError is called for invalid return URLs and API errors. By default it responds with 400 status for
+	// invalid URLs and 502 status for API errors.
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewRedirectionHandler creates redirection handler.
+func NewRedirectionHandler(payments PaymentAPI, signer *ReturnURLSigner, onOutcome func(w http.ResponseWriter, r *http.Request, outcome *RedirectionOutcome)) *RedirectionHandler {
+	return &RedirectionHandler{Payments: payments, Signer: signer, OnOutcome: onOutcome}
+}
+
+// ServeHTTP implements http.Handler interface.
+func (h *RedirectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state, err := h.Signer.Verify(r.URL)
+	if err != nil {
+		h.fail(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.Payments.Get(r.Context(), state.PaymentID, PaymentExpandAll)
+	if err != nil {
+		h.fail(w, r, errors.Wrapf(err, "failed to get payment %s", state.PaymentID), http.StatusBadGateway)
+		return
+	}
+
+	outcome := NewRedirectionOutcome(payment, *state)
+	if h.OnOutcome == nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(outcome.Status))
+		return
+	}
+	h.OnOutcome(w, r, outcome)
+}
+
+func (h *RedirectionHandler) fail(w http.ResponseWriter, r *http.Request, err error, status int) {
+	if h.OnError != nil {
+		h.OnError(w, r, err)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// NewRedirectionOutcome determines redirection outcome by the latest transaction of expanded payment.
+func NewRedirectionOutcome(payment *Payment, state ReturnState) *RedirectionOutcome {
+	outcome := &RedirectionOutcome{
+		Status:      RedirectionOutcomePending,
+		ReturnState: state,
+		Payment:     payment,
+	}
+
+	resources := payment.RelatedResources
+	if resources == nil {
+		return outcome
+	}
+
+	var result *Result
+	var latest Timestamp
+	for i := range resources.Authorizations {
+		if a := &resources.Authorizations[i]; result == nil || !a.Created.Before(latest) {
+			outcome.Authorization, outcome.Charge = a, nil
+			result, latest = &a.Result, a.Created
+		}
+	}
+	for i := range resources.Charges {
+		if c := &resources.Charges[i]; result == nil || !c.Created.Before(latest) {
+			outcome.Authorization, outcome.Charge = nil, c
+			result, latest = &c.Result, c.Created
+		}
+	}
+	for i := range resources.Redirections {
+		if red := &resources.Redirections[i]; outcome.Redirection == nil || !red.Created.Before(outcome.Redirection.Created) {
+			outcome.Redirection = red
+		}
+	}
+
+	switch {
+	case result == nil || result.IsPending():
+		outcome.Status = RedirectionOutcomePending
+	case result.IsApproved():
+		outcome.Status = RedirectionOutcomeApproved
+	default:
+		outcome.Status = RedirectionOutcomeDeclined
+	}
+	return outcome
+}
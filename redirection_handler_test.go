@@ -0,0 +1,133 @@
+package zooz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testReturnURLKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestReturnURLSigner(t *testing.T, ttl time.Duration) *ReturnURLSigner {
+	signer, err := NewReturnURLSigner(testReturnURLKey, ttl)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	return signer
+}
+
+func TestNewReturnURLSigner(t *testing.T) {
+	if _, err := NewReturnURLSigner(nil, 0); err == nil {
+		t.Error("Error expected for empty key")
+	}
+	if _, err := NewReturnURLSigner([]byte("secret"), 0); err == nil {
+		t.Error("Error expected for short key")
+	}
+}
+
+func TestReturnURLSigner(t *testing.T) {
+	signer := newTestReturnURLSigner(t, time.Hour)
+	signer.now = func() time.Time { return time.Unix(1514550000, 0) }
+
+	signed, err := signer.Sign("https://shop.example.com/return?lang=en", "payment_id", "order-1")
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	u, _ := url.Parse(signed)
+	if u.Query().Get("lang") != "en" {
+		t.Errorf("Original query is lost: %s", signed)
+	}
+
+	// Provider may add own params.
+	query := u.Query()
+	query.Set("status", "Succeed")
+	u.RawQuery = query.Encode()
+
+	state, err := signer.Verify(u)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if state.PaymentID != "payment_id" || state.State != "order-1" || state.ExpiresAt.Unix() != 1514553600 {
+		t.Errorf("State is not as expected: %+v", state)
+	}
+
+	tampered, _ := url.Parse(strings.Replace(signed, "order-1", "order-2", 1))
+	if _, err := signer.Verify(tampered); err == nil {
+		t.Error("Tampered URL must be invalid")
+	}
+
+	signer.now = func() time.Time { return time.Unix(1514560000, 0) }
+	if _, err := signer.Verify(u); err == nil {
+		t.Error("Expired URL must be invalid")
+	}
+}
+
+func TestReturnURLSigner_signature(t *testing.T) {
+	signer := newTestReturnURLSigner(t, 0)
+	if signer.signature("/return", "payment\nstate", "", "") == signer.signature("/return", "payment", "state\n", "") {
+		t.Error("Signatures of different fields must differ")
+	}
+}
+
+func TestRedirectionHandler(t *testing.T) {
+	signer := newTestReturnURLSigner(t, 0)
+	signed, err := signer.Sign("https://shop.example.com/return", "payment_id", "order-1")
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	mock := NewMock()
+	mock.On("Payment.Get", "payment_id", []PaymentExpand{PaymentExpandAll}).Return(&Payment{
+		ID:     "payment_id",
+		Status: PaymentStatusAuthorized,
+		RelatedResources: &PaymentRelatedResources{
+			Authorizations: []Authorization{
+				{ID: "first", Created: TimestampFromMillis(1), Result: Result{Status: ResultStatusFailed}},
+				{ID: "second", Created: TimestampFromMillis(2), Result: Result{Status: ResultStatusSucceed}},
+			},
+			Redirections: []Redirection{{ID: "redirection_id", Created: TimestampFromMillis(1)}},
+		},
+	}, nil).Once()
+
+	var outcome *RedirectionOutcome
+	handler := NewRedirectionHandler(mock.Payment(), signer, func(w http.ResponseWriter, r *http.Request, o *RedirectionOutcome) {
+		outcome = o
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", signed, nil))
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Invalid status code: %d", recorder.Code)
+	}
+	if outcome == nil {
+		t.Fatal("Callback is not called")
+	}
+	if outcome.Status != RedirectionOutcomeApproved || outcome.State != "order-1" {
+		t.Errorf("Outcome is not as expected: %+v", outcome)
+	}
+	if outcome.Authorization.ID != "second" || outcome.Redirection.ID != "redirection_id" {
+		t.Errorf("Outcome transactions are not as expected: %+v", outcome)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "https://shop.example.com/return?zooz_payment_id=payment_id", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Invalid status code for unsigned URL: %d", recorder.Code)
+	}
+	mock.AssertExpectations(t)
+
+	// Default response without callback.
+	mock.On("Payment.Get", "payment_id", []PaymentExpand{PaymentExpandAll}).Return(&Payment{ID: "payment_id"}, nil).Once()
+	handler.OnOutcome = nil
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", signed, nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != string(RedirectionOutcomePending) {
+		t.Errorf("Default response is not as expected: %d %s", recorder.Code, recorder.Body)
+	}
+}
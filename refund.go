@@ -15,13 +15,23 @@ type RefundClient struct {
 // Refund is a entity model.
 type Refund struct {
 	RefundParams
+	RawFields
 
 	ID           string       `json:"id"`
 	Result       Result       `json:"result"`
-	Created      json.Number  `json:"created"`
+	Created      Timestamp    `json:"created"`
 	ProviderData ProviderData `json:"provider_data"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (r *Refund) UnmarshalJSON(data []byte) error {
+	type model Refund
+	if err := json.Unmarshal(data, (*model)(r)); err != nil {
+		return err
+	}
+	return r.RawFields.decode(data, r)
+}
+
 // RefundParams is a set of params for creating entity.
 type RefundParams struct {
 	ReconciliationID string `json:"reconciliation_id,omitempty"`
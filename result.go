@@ -0,0 +1,91 @@
+package zooz
+
+// Result represents status and category of some methods response.
+// https://developers.paymentsos.com/docs/api#/introduction/responses/response-status-and-result
+type Result struct {
+	Status      ResultStatus      `json:"status"`
+	Category    ResultCategory    `json:"category"`
+	SubCategory ResultSubCategory `json:"sub_category"`
+	Description string            `json:"description"`
+}
+
+// ResultStatus is a type of result status.
+type ResultStatus string
+
+// ResultCategory is a type of result category, it describes the reason of failure.
+type ResultCategory string
+
+// ResultSubCategory is a type of result sub-category, it details the reason of failure.
+type ResultSubCategory string
+
+// List of possible result status values.
+const (
+	ResultStatusSucceed ResultStatus = "Succeed"
+	ResultStatusFailed  ResultStatus = "Failed"
+	ResultStatusPending ResultStatus = "Pending"
+)
+
+// List of possible result category values.
+const (
+	ResultCategoryProviderError               ResultCategory = "provider_error"
+	ResultCategoryProviderNetworkError        ResultCategory = "provider_network_error"
+	ResultCategoryProviderAuthenticationError ResultCategory = "provider_authentication_error"
+	ResultCategoryPaymentMethodDeclined       ResultCategory = "payment_method_declined"
+	ResultCategoryRiskDeclined                ResultCategory = "risk_declined"
+	ResultCategoryThreeDSecureFailed          ResultCategory = "three_d_secure_failed"
+)
+
+// List of known result sub-category values.
+const (
+	ResultSubCategoryInsufficientFunds      ResultSubCategory = "insufficient_funds"
+	ResultSubCategoryExceedsWithdrawalLimit ResultSubCategory = "exceeds_withdrawal_limit"
+	ResultSubCategoryIssuerUnavailable      ResultSubCategory = "issuer_unavailable"
+	ResultSubCategoryTryAgainLater          ResultSubCategory = "try_again_later"
+	ResultSubCategoryDoNotHonor             ResultSubCategory = "do_not_honor"
+	ResultSubCategoryCardExpired            ResultSubCategory = "card_expired"
+	ResultSubCategoryInvalidCardNumber      ResultSubCategory = "invalid_card_number"
+	ResultSubCategoryInvalidCvv             ResultSubCategory = "invalid_cvv"
+	ResultSubCategoryLostOrStolen           ResultSubCategory = "lost_or_stolen"
+	ResultSubCategoryRestrictedCard         ResultSubCategory = "restricted_card"
+	ResultSubCategoryFraudSuspected         ResultSubCategory = "fraud_suspected"
+	ResultSubCategoryTransactionNotAllowed  ResultSubCategory = "transaction_not_allowed"
+)
+
+// IsApproved reports whether operation succeeded.
+func (r Result) IsApproved() bool {
+	return r.Status == ResultStatusSucceed
+}
+
+// IsPending reports whether operation is not finished yet, e.g. waits for redirection or asynchronous provider.
+func (r Result) IsPending() bool {
+	return r.Status == ResultStatusPending
+}
+
+// IsFailed reports whether operation failed for any reason.
+func (r Result) IsFailed() bool {
+	return r.Status == ResultStatusFailed
+}
+
+// IsSoftDecline reports whether operation failed for temporary reason: provider or network error,
+// or decline which may succeed later with the same payment method (e.g. insufficient funds).
+// Result is classified by NormalizeDecline without provider response code.
+func (r Result) IsSoftDecline() bool {
+	return NormalizeDecline(r, ProviderData{}).Class == DeclineClassSoft
+}
+
+// IsHardDecline reports whether operation failed and retry with the same payment method is pointless,
+// e.g. card is expired, stolen or declined by risk checks.
+// Result is classified by NormalizeDecline without provider response code.
+func (r Result) IsHardDecline() bool {
+	return NormalizeDecline(r, ProviderData{}).Class == DeclineClassHard
+}
+
+// IsPartialApproval reports whether authorization is approved for lower amount than requested.
+func (a *Authorization) IsPartialApproval(requestedAmount int64) bool {
+	return a.Result.IsApproved() && a.Amount > 0 && a.Amount < requestedAmount
+}
+
+// IsPartialApproval reports whether charge is approved for lower amount than requested.
+func (c *Charge) IsPartialApproval(requestedAmount int64) bool {
+	return c.Result.IsApproved() && c.Amount > 0 && c.Amount < requestedAmount
+}
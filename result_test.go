@@ -0,0 +1,90 @@
+package zooz
+
+import "testing"
+
+func TestResult_Helpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   Result
+		approved bool
+		pending  bool
+		soft     bool
+		hard     bool
+	}{
+		{
+			name:     "succeed",
+			result:   Result{Status: ResultStatusSucceed},
+			approved: true,
+		},
+		{
+			name:    "pending",
+			result:  Result{Status: ResultStatusPending},
+			pending: true,
+		},
+		{
+			name:   "insufficient funds",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryInsufficientFunds},
+			soft:   true,
+		},
+		{
+			name:   "provider network error",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderNetworkError},
+			soft:   true,
+		},
+		{
+			name:   "card expired",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryCardExpired},
+			hard:   true,
+		},
+		{
+			name:   "unknown decline",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: "something_new"},
+			hard:   true,
+		},
+		{
+			name:   "provider error with hard sub category",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderError, SubCategory: ResultSubCategoryInvalidCardNumber},
+			hard:   true,
+		},
+		{
+			name:   "risk declined",
+			result: Result{Status: ResultStatusFailed, Category: ResultCategoryRiskDeclined},
+			hard:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.result.IsApproved() != test.approved {
+				t.Errorf("Invalid IsApproved: %v", test.result.IsApproved())
+			}
+			if test.result.IsPending() != test.pending {
+				t.Errorf("Invalid IsPending: %v", test.result.IsPending())
+			}
+			if test.result.IsSoftDecline() != test.soft {
+				t.Errorf("Invalid IsSoftDecline: %v", test.result.IsSoftDecline())
+			}
+			if test.result.IsHardDecline() != test.hard {
+				t.Errorf("Invalid IsHardDecline: %v", test.result.IsHardDecline())
+			}
+			if decline := NormalizeDecline(test.result, ProviderData{}); decline.IsRetryable() != test.soft {
+				t.Errorf("IsSoftDecline disagrees with NormalizeDecline: %+v", decline)
+			}
+		})
+	}
+}
+
+func TestIsPartialApproval(t *testing.T) {
+	authorization := &Authorization{Result: Result{Status: ResultStatusSucceed}, Amount: 700}
+	if !authorization.IsPartialApproval(1000) {
+		t.Error("Authorization must be partially approved")
+	}
+	if authorization.IsPartialApproval(700) {
+		t.Error("Authorization must be fully approved")
+	}
+
+	charge := &Charge{Result: Result{Status: ResultStatusFailed}, Amount: 700}
+	if charge.IsPartialApproval(1000) {
+		t.Error("Failed charge must not be partially approved")
+	}
+}
@@ -0,0 +1,365 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RiskOutcome is a decision of RiskEvaluator about transaction.
+type RiskOutcome string
+
+// List of possible risk outcomes, from the least to the most severe.
+const (
+	RiskOutcomeApprove RiskOutcome = "approve"
+	// RiskOutcomeRequire3DS allows transaction only with 3-D Secure authentication of cardholder.
+	RiskOutcomeRequire3DS RiskOutcome = "require_3ds"
+	RiskOutcomeReject     RiskOutcome = "reject"
+)
+
+var riskOutcomeSeverity = map[RiskOutcome]int{
+	RiskOutcomeApprove:    0,
+	RiskOutcomeRequire3DS: 1,
+	RiskOutcomeReject:     2,
+}
+
+// RiskOperation is a type of transaction evaluated by RiskEvaluator.
+type RiskOperation string
+
+// List of evaluated operations.
+const (
+	RiskOperationAuthorization RiskOperation = "authorization"
+	RiskOperationCharge        RiskOperation = "charge"
+)
+
+// RiskRequest describes transaction which is going to be sent to API.
+type RiskRequest struct {
+	Operation RiskOperation
+	// IdempotencyKey of transaction. Retries of the same transaction have the same key.
+	IdempotencyKey string
+	PaymentID      string
+	Payment        *Payment
+	PaymentMethod  PaymentMethodDetails
+	// StoredPaymentMethod is a payment method of payment customer with BIN, country and issuer of card.
+	// It is nil if caller doesn't have it.
+	StoredPaymentMethod *PaymentMethod
+	ClientInfo          *ClientInfo
+	ThreeDSecure        *ThreeDSecureAttributes
+}
+
+// BIN returns bank identification number of card, if known.
+func (r RiskRequest) BIN() string {
+	if r.StoredPaymentMethod == nil {
+		return ""
+	}
+	return r.StoredPaymentMethod.BinNumber.String()
+}
+
+// Country returns country of card issuer, if known.
+func (r RiskRequest) Country() string {
+	if r.StoredPaymentMethod == nil {
+		return ""
+	}
+	return r.StoredPaymentMethod.CountryCode
+}
+
+// Issuer returns name of card issuer, if known.
+func (r RiskRequest) Issuer() string {
+	if r.StoredPaymentMethod == nil {
+		return ""
+	}
+	return r.StoredPaymentMethod.Issuer
+}
+
+// IPAddress returns IP address of customer.
+func (r RiskRequest) IPAddress() string {
+	if r.ClientInfo == nil {
+		return ""
+	}
+	return r.ClientInfo.IPAddress
+}
+
+// CustomerID returns ID of payment customer.
+func (r RiskRequest) CustomerID() string {
+	if r.Payment == nil {
+		return ""
+	}
+	return r.Payment.CustomerID
+}
+
+// CardKey returns identifier of card: BIN, last 4 digits and expiration date if payment method is stored,
+// or token otherwise.
+func (r RiskRequest) CardKey() string {
+	if pm := r.StoredPaymentMethod; pm != nil && pm.Last4Digits != "" {
+		return fmt.Sprintf("%s/%s/%s", pm.BinNumber, pm.Last4Digits, pm.ExpirationDate)
+	}
+	return r.PaymentMethod.Token
+}
+
+// RiskDecision is a result of risk evaluation.
+type RiskDecision struct {
+	Outcome RiskOutcome
+	// Reasons are names of triggered rules.
+	Reasons []string
+}
+
+// RiskEvaluator evaluates transaction before it is sent to API.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, request RiskRequest) (RiskDecision, error)
+}
+
+// RiskEvaluatorFunc is an adapter to use ordinary function as RiskEvaluator.
+type RiskEvaluatorFunc func(ctx context.Context, request RiskRequest) (RiskDecision, error)
+
+// Evaluate implements RiskEvaluator interface.
+func (f RiskEvaluatorFunc) Evaluate(ctx context.Context, request RiskRequest) (RiskDecision, error) {
+	return f(ctx, request)
+}
+
+// RiskError is returned by RiskGuardedClient when transaction is rejected by
+// RiskEvaluator, or requires 3-D Secure but is sent without 3-D Secure attributes.
+type RiskError struct {
+	PaymentID string
+	Decision  RiskDecision
+}
+
+// Error implements error interface.
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("transaction of payment %s is declined by risk evaluation: %s [%s]", e.PaymentID, e.Decision.Outcome, strings.Join(e.Decision.Reasons, ", "))
+}
+
+// RiskGuardedClient evaluates authorizations and charges by RiskEvaluator before they are sent to API.
+// Payment and stored payment method are passed by caller, no additional requests are made.
+type RiskGuardedClient struct {
+	API       API
+	Evaluator RiskEvaluator
+}
+
+// NewRiskGuardedClient creates RiskGuardedClient on top of given API.
+func NewRiskGuardedClient(api API, evaluator RiskEvaluator) *RiskGuardedClient {
+	return &RiskGuardedClient{API: api, Evaluator: evaluator}
+}
+
+// Authorize creates new Authorization entity for given payment if it is not rejected by evaluator.
+// Stored payment method may be nil.
+func (g *RiskGuardedClient) Authorize(ctx context.Context, idempotencyKey string, payment *Payment, storedPaymentMethod *PaymentMethod, params *AuthorizationParams, clientInfo *ClientInfo) (*Authorization, error) {
+	var checked AuthorizationParams
+	if params != nil {
+		checked = *params
+		params = &checked
+	}
+	err := g.evaluate(ctx, RiskOperationAuthorization, idempotencyKey, payment, storedPaymentMethod, checked.PaymentMethod, &checked.ThreeDSecureAttributes, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+	return g.API.Authorization().New(ctx, idempotencyKey, payment.ID, params, clientInfo)
+}
+
+// Charge creates new Charge entity for given payment if it is not rejected by evaluator.
+// Stored payment method may be nil.
+func (g *RiskGuardedClient) Charge(ctx context.Context, idempotencyKey string, payment *Payment, storedPaymentMethod *PaymentMethod, params *ChargeParams, clientInfo *ClientInfo) (*Charge, error) {
+	var checked ChargeParams
+	if params != nil {
+		checked = *params
+		params = &checked
+	}
+	err := g.evaluate(ctx, RiskOperationCharge, idempotencyKey, payment, storedPaymentMethod, checked.PaymentMethod, &checked.ThreeDSecureAttributes, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+	return g.API.Charge().New(ctx, idempotencyKey, payment.ID, params, clientInfo)
+}
+
+// evaluate calls evaluator and replaces threeDSecure with attributes to send with transaction.
+func (g *RiskGuardedClient) evaluate(ctx context.Context, operation RiskOperation, idempotencyKey string, payment *Payment, storedPaymentMethod *PaymentMethod, paymentMethod PaymentMethodDetails, threeDSecure **ThreeDSecureAttributes, clientInfo *ClientInfo) error {
+	if payment == nil {
+		return errors.New("payment is required for risk evaluation")
+	}
+
+	request := RiskRequest{
+		Operation:           operation,
+		IdempotencyKey:      idempotencyKey,
+		PaymentID:           payment.ID,
+		Payment:             payment,
+		PaymentMethod:       paymentMethod,
+		StoredPaymentMethod: storedPaymentMethod,
+		ClientInfo:          clientInfo,
+		ThreeDSecure:        *threeDSecure,
+	}
+	decision, err := g.Evaluator.Evaluate(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to evaluate risk")
+	}
+
+	switch decision.Outcome {
+	case RiskOutcomeReject:
+		return &RiskError{PaymentID: payment.ID, Decision: decision}
+	case RiskOutcomeRequire3DS:
+		required, err := requireThreeDSecure(payment.ID, decision, *threeDSecure)
+		if err != nil {
+			return err
+		}
+		*threeDSecure = required
+	}
+	return nil
+}
+
+// requireThreeDSecure requests challenge for internal 3-D Secure and checks that external 3-D Secure 2 is
+// authenticated. 3-D Secure 1 attributes are accepted if they carry CAVV or XID of performed authentication.
+func requireThreeDSecure(paymentID string, decision RiskDecision, attributes *ThreeDSecureAttributes) (*ThreeDSecureAttributes, error) {
+	switch {
+	case attributes != nil && attributes.Internal != nil:
+		internal := *attributes.Internal
+		if internal.ChallengeIndicator != ThreeDSecureChallengeMandated {
+			internal.ChallengeIndicator = ThreeDSecureChallengeRequested
+		}
+		required := *attributes
+		required.Internal = &internal
+		return &required, nil
+	case attributes != nil && attributes.External != nil && attributes.External.AuthenticationStatus.IsAuthenticated():
+		return attributes, nil
+	case attributes != nil && attributes.External == nil && (attributes.CAVV != "" || attributes.XID != ""):
+		return attributes, nil
+	}
+	return nil, &RiskError{PaymentID: paymentID, Decision: decision}
+}
+
+// RiskKey is a property of transaction counted by velocity rules.
+type RiskKey string
+
+// List of possible velocity rule keys.
+const (
+	RiskKeyCard     RiskKey = "card"
+	RiskKeyIP       RiskKey = "ip"
+	RiskKeyCustomer RiskKey = "customer"
+)
+
+func (k RiskKey) value(request RiskRequest) string {
+	switch k {
+	case RiskKeyCard:
+		return request.CardKey()
+	case RiskKeyIP:
+		return request.IPAddress()
+	case RiskKeyCustomer:
+		return request.CustomerID()
+	}
+	return ""
+}
+
+// VelocityRule limits number of transactions with the same key within time window.
+type VelocityRule struct {
+	Name   string
+	Key    RiskKey
+	Window time.Duration
+	// Limit is the maximal number of attempts within window. Further attempts get Outcome.
+	Limit   int
+	Outcome RiskOutcome
+}
+
+// VelocityEngine is in-memory RiskEvaluator counting transaction attempts by velocity rules. Every evaluated
+// transaction counts as an attempt, whatever its outcome is, except retries with already seen idempotency key.
+// It doesn't survive process restart and is intended for single-process usage.
+type VelocityEngine struct {
+	Rules []VelocityRule
+	// Now returns current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	attempts map[string][]time.Time
+	// seen are idempotency keys of counted attempts with time of the first attempt.
+	seen map[string]time.Time
+}
+
+// NewVelocityEngine creates VelocityEngine with given rules.
+func NewVelocityEngine(rules ...VelocityRule) *VelocityEngine {
+	return &VelocityEngine{Rules: rules}
+}
+
+// Evaluate implements RiskEvaluator interface. The most severe outcome of triggered rules is returned.
+func (e *VelocityEngine) Evaluate(ctx context.Context, request RiskRequest) (RiskDecision, error) {
+	now := time.Now()
+	if e.Now != nil {
+		now = e.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.attempts == nil {
+		e.attempts = map[string][]time.Time{}
+		e.seen = map[string]time.Time{}
+	}
+
+	// Retry of the same transaction is evaluated against current counters, but is not counted again.
+	retry := false
+	if request.IdempotencyKey != "" {
+		_, retry = e.seen[request.IdempotencyKey]
+		if !retry {
+			e.seen[request.IdempotencyKey] = now
+		}
+	}
+
+	decision := RiskDecision{Outcome: RiskOutcomeApprove}
+	counted := map[string]bool{}
+	for _, rule := range e.Rules {
+		value := rule.Key.value(request)
+		if value == "" {
+			continue
+		}
+		key := string(rule.Key) + "/" + value
+		if !retry && !counted[key] {
+			e.attempts[key] = append(e.attempts[key], now)
+			counted[key] = true
+		}
+
+		if countSince(e.attempts[key], now.Add(-rule.Window)) > rule.Limit {
+			decision.Reasons = append(decision.Reasons, rule.Name)
+			if riskOutcomeSeverity[rule.Outcome] > riskOutcomeSeverity[decision.Outcome] {
+				decision.Outcome = rule.Outcome
+			}
+		}
+	}
+
+	e.prune(now)
+	return decision, nil
+}
+
+// prune forgets attempts and idempotency keys which are out of the longest window.
+func (e *VelocityEngine) prune(now time.Time) {
+	var window time.Duration
+	for _, rule := range e.Rules {
+		if rule.Window > window {
+			window = rule.Window
+		}
+	}
+	since := now.Add(-window)
+	for key, attempts := range e.attempts {
+		i := 0
+		for i < len(attempts) && !attempts[i].After(since) {
+			i++
+		}
+		if i == len(attempts) {
+			delete(e.attempts, key)
+		} else if i > 0 {
+			e.attempts[key] = append([]time.Time(nil), attempts[i:]...)
+		}
+	}
+	for key, t := range e.seen {
+		if !t.After(since) {
+			delete(e.seen, key)
+		}
+	}
+}
+
+func countSince(attempts []time.Time, since time.Time) int {
+	count := 0
+	for _, t := range attempts {
+		if t.After(since) {
+			count++
+		}
+	}
+	return count
+}
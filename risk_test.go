@@ -0,0 +1,141 @@
+package zooz
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVelocityEngine_Evaluate(t *testing.T) {
+	now := time.Date(2018, time.January, 4, 12, 0, 0, 0, time.UTC)
+	engine := NewVelocityEngine(
+		VelocityRule{Name: "card_3ds", Key: RiskKeyCard, Window: time.Hour, Limit: 2, Outcome: RiskOutcomeRequire3DS},
+		VelocityRule{Name: "card_reject", Key: RiskKeyCard, Window: time.Hour, Limit: 3, Outcome: RiskOutcomeReject},
+		VelocityRule{Name: "ip", Key: RiskKeyIP, Window: 10 * time.Minute, Limit: 1, Outcome: RiskOutcomeReject},
+	)
+	engine.Now = func() time.Time { return now }
+
+	request := func(token, ip string) RiskRequest {
+		return RiskRequest{PaymentMethod: PaymentMethodDetails{Token: token}, ClientInfo: &ClientInfo{IPAddress: ip}}
+	}
+
+	tests := []struct {
+		request  RiskRequest
+		after    time.Duration
+		expected RiskDecision
+	}{
+		{request: request("card", "ip1"), expected: RiskDecision{Outcome: RiskOutcomeApprove}},
+		{request: request("card", "ip2"), after: time.Minute, expected: RiskDecision{Outcome: RiskOutcomeApprove}},
+		{request: request("card", "ip3"), after: time.Minute, expected: RiskDecision{Outcome: RiskOutcomeRequire3DS, Reasons: []string{"card_3ds"}}},
+		{request: request("card", "ip3"), after: time.Minute, expected: RiskDecision{Outcome: RiskOutcomeReject, Reasons: []string{"card_3ds", "card_reject", "ip"}}},
+		{request: request("other", "ip3"), after: 11 * time.Minute, expected: RiskDecision{Outcome: RiskOutcomeApprove}},
+		{request: request("card", "ip4"), after: time.Hour, expected: RiskDecision{Outcome: RiskOutcomeApprove}},
+	}
+
+	for i, tt := range tests {
+		now = now.Add(tt.after)
+		decision, err := engine.Evaluate(context.Background(), tt.request)
+		if err != nil {
+			t.Fatalf("Error must be nil: %s", err)
+		}
+		if !reflect.DeepEqual(decision, tt.expected) {
+			t.Errorf("Decision of attempt %d is %+v, expected %+v", i, decision, tt.expected)
+		}
+	}
+}
+
+func TestRiskRequest_CardKey(t *testing.T) {
+	r := RiskRequest{
+		PaymentMethod:       PaymentMethodDetails{Token: "token"},
+		StoredPaymentMethod: &PaymentMethod{BinNumber: json.Number("411111"), Last4Digits: "1111", ExpirationDate: "12/2030", CountryCode: "USA", Issuer: "Bank"},
+	}
+	if r.CardKey() != "411111/1111/12/2030" || r.BIN() != "411111" || r.Country() != "USA" || r.Issuer() != "Bank" {
+		t.Errorf("Card properties are not as expected: %s %s %s %s", r.CardKey(), r.BIN(), r.Country(), r.Issuer())
+	}
+
+	r.StoredPaymentMethod = nil
+	if r.CardKey() != "token" || r.BIN() != "" {
+		t.Errorf("Card key is not as expected: %s", r.CardKey())
+	}
+}
+
+func TestVelocityEngine_Evaluate_Retry(t *testing.T) {
+	engine := NewVelocityEngine(VelocityRule{Name: "card", Key: RiskKeyCard, Window: time.Hour, Limit: 1, Outcome: RiskOutcomeReject})
+
+	request := RiskRequest{IdempotencyKey: "key", PaymentMethod: PaymentMethodDetails{Token: "card"}}
+	for i := 0; i < 3; i++ {
+		decision, err := engine.Evaluate(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Error must be nil: %s", err)
+		}
+		if decision.Outcome != RiskOutcomeApprove {
+			t.Errorf("Retry %d must not be counted: %+v", i, decision)
+		}
+	}
+
+	request.IdempotencyKey = "other"
+	if decision, _ := engine.Evaluate(context.Background(), request); decision.Outcome != RiskOutcomeReject {
+		t.Errorf("New attempt must be counted: %+v", decision)
+	}
+}
+
+func TestRiskGuardedClient_Authorize(t *testing.T) {
+	var outcome RiskOutcome
+	var evaluated RiskRequest
+	m := NewMock()
+	g := NewRiskGuardedClient(m, RiskEvaluatorFunc(func(ctx context.Context, request RiskRequest) (RiskDecision, error) {
+		evaluated = request
+		return RiskDecision{Outcome: outcome, Reasons: []string{"rule"}}, nil
+	}))
+
+	payment := &Payment{ID: "payment_id", PaymentParams: PaymentParams{CustomerID: "customer"}}
+	params := &AuthorizationParams{PaymentMethod: PaymentMethodDetails{Type: "tokenized", Token: "token"}}
+	clientInfo := &ClientInfo{IPAddress: "ip"}
+
+	m.On("Authorization.New", "key", "payment_id", params, clientInfo).Return(&Authorization{ID: "id"}, nil).Once()
+	outcome = RiskOutcomeApprove
+	if _, err := g.Authorize(context.Background(), "key", payment, nil, params, clientInfo); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if evaluated.Operation != RiskOperationAuthorization || evaluated.IdempotencyKey != "key" || evaluated.CustomerID() != "customer" || evaluated.IPAddress() != "ip" {
+		t.Errorf("Risk request is not as expected: %+v", evaluated)
+	}
+
+	outcome = RiskOutcomeReject
+	_, err := g.Authorize(context.Background(), "key", payment, nil, params, clientInfo)
+	if riskErr, ok := err.(*RiskError); !ok || riskErr.Decision.Outcome != RiskOutcomeReject {
+		t.Errorf("Risk error expected: %v", err)
+	}
+
+	outcome = RiskOutcomeRequire3DS
+	if _, err := g.Authorize(context.Background(), "key", payment, nil, params, clientInfo); err == nil {
+		t.Errorf("Error expected for transaction without 3-D Secure")
+	}
+
+	params.ThreeDSecureAttributes = &ThreeDSecureAttributes{Internal: &ThreeDSecureInternal{ChallengeIndicator: ThreeDSecureChallengeNoPreference}}
+	m.On("Authorization.New", "key", "payment_id", &AuthorizationParams{
+		PaymentMethod:          params.PaymentMethod,
+		ThreeDSecureAttributes: &ThreeDSecureAttributes{Internal: &ThreeDSecureInternal{ChallengeIndicator: ThreeDSecureChallengeRequested}},
+	}, clientInfo).Return(&Authorization{ID: "id"}, nil).Once()
+	if _, err := g.Authorize(context.Background(), "key", payment, nil, params, clientInfo); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if params.ThreeDSecureAttributes.Internal.ChallengeIndicator != ThreeDSecureChallengeNoPreference {
+		t.Errorf("Params must not be modified")
+	}
+
+	// Authenticated 3-D Secure 1 is accepted.
+	params.ThreeDSecureAttributes = &ThreeDSecureAttributes{CAVV: "cavv", XID: "xid", EciFlag: "05"}
+	m.On("Authorization.New", "key", "payment_id", params, clientInfo).Return(&Authorization{ID: "id"}, nil).Once()
+	if _, err := g.Authorize(context.Background(), "key", payment, nil, params, clientInfo); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	if _, err := g.Charge(context.Background(), "key", nil, nil, &ChargeParams{}, clientInfo); err == nil {
+		t.Error("Error expected for nil payment")
+	}
+
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,101 @@
+package zooz
+
+// StoredCredentialUsage tells whether stored payment method is used for the first time or not.
+type StoredCredentialUsage string
+
+// List of possible stored credential usages.
+const (
+	// StoredCredentialInitial is the first transaction, when cardholder agrees to store payment method.
+	StoredCredentialInitial StoredCredentialUsage = "initial"
+	// StoredCredentialSubsequent is any later transaction with stored payment method.
+	StoredCredentialSubsequent StoredCredentialUsage = "subsequent"
+)
+
+// TransactionInitiator tells who initiated the transaction.
+type TransactionInitiator string
+
+// List of possible transaction initiators.
+const (
+	// TransactionInitiatorCustomer is a customer-initiated transaction (CIT), cardholder is present.
+	TransactionInitiatorCustomer TransactionInitiator = "customer"
+	// TransactionInitiatorMerchant is a merchant-initiated transaction (MIT), cardholder is not present.
+	TransactionInitiatorMerchant TransactionInitiator = "merchant"
+)
+
+// StoredCredentialReason is a type of agreement stored payment method is used under.
+type StoredCredentialReason string
+
+// List of possible stored credential reasons.
+const (
+	StoredCredentialRecurring   StoredCredentialReason = "recurring"
+	StoredCredentialInstallment StoredCredentialReason = "installment"
+	StoredCredentialUnscheduled StoredCredentialReason = "unscheduled"
+)
+
+// StoredCredential is a set of card-on-file indicators for authorizations and charges with stored payment method.
+// Subsequent merchant-initiated transactions should refer to network transaction ID of the initial transaction,
+// which is returned in ProviderData.NetworkTransactionID.
+type StoredCredential struct {
+	Usage                StoredCredentialUsage  `json:"usage"`
+	Initiator            TransactionInitiator   `json:"initiator"`
+	Reason               StoredCredentialReason `json:"reason,omitempty"`
+	NetworkTransactionID string                 `json:"network_transaction_id,omitempty"`
+}
+
+// NewInitialStoredCredential returns indicators of customer-initiated transaction which stores payment method
+// for later use under given agreement.
+func NewInitialStoredCredential(reason StoredCredentialReason) *StoredCredential {
+	return &StoredCredential{
+		Usage:     StoredCredentialInitial,
+		Initiator: TransactionInitiatorCustomer,
+		Reason:    reason,
+	}
+}
+
+// NewMerchantInitiatedStoredCredential returns indicators of merchant-initiated transaction with stored payment
+// method, chained to the initial transaction by its network transaction ID.
+func NewMerchantInitiatedStoredCredential(reason StoredCredentialReason, networkTransactionID string) *StoredCredential {
+	return &StoredCredential{
+		Usage:                StoredCredentialSubsequent,
+		Initiator:            TransactionInitiatorMerchant,
+		Reason:               reason,
+		NetworkTransactionID: networkTransactionID,
+	}
+}
+
+// Validate checks stored credential indicators and returns ValidationErrors if some fields are invalid.
+func (s *StoredCredential) Validate() error {
+	var v validation
+	switch s.Usage {
+	case StoredCredentialInitial, StoredCredentialSubsequent:
+	default:
+		v.add("usage", "%q is not valid stored credential usage", s.Usage)
+	}
+	switch s.Initiator {
+	case TransactionInitiatorCustomer, TransactionInitiatorMerchant:
+	default:
+		v.add("initiator", "%q is not valid transaction initiator", s.Initiator)
+	}
+	if s.Usage == StoredCredentialInitial && s.Initiator == TransactionInitiatorMerchant {
+		v.add("initiator", "initial transaction must be initiated by customer")
+	}
+	if s.Initiator == TransactionInitiatorMerchant && s.Reason == "" {
+		v.add("reason", "is required for merchant-initiated transaction")
+	}
+	if s.Usage == StoredCredentialSubsequent && s.Initiator == TransactionInitiatorMerchant && s.NetworkTransactionID == "" {
+		v.add("network_transaction_id", "is required for subsequent merchant-initiated transaction")
+	}
+	return v.err()
+}
+
+// NetworkTransactionID returns network transaction ID of the authorization, which chains subsequent
+// merchant-initiated transactions to it.
+func (a *Authorization) NetworkTransactionID() string {
+	return a.ProviderData.NetworkTransactionID
+}
+
+// NetworkTransactionID returns network transaction ID of the charge, which chains subsequent
+// merchant-initiated transactions to it.
+func (c *Charge) NetworkTransactionID() string {
+	return c.ProviderData.NetworkTransactionID
+}
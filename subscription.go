@@ -0,0 +1,289 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SubscriptionStatus is a type of subscription status.
+type SubscriptionStatus string
+
+// List of possible subscription statuses.
+const (
+	SubscriptionStatusActive SubscriptionStatus = "active"
+	// SubscriptionStatusPastDue means the last billing cycle was declined. Scheduler keeps retrying it on every run,
+	// or according to the policy of dunning engine.
+	SubscriptionStatusPastDue SubscriptionStatus = "past_due"
+	// SubscriptionStatusUnpaid means retries of declined cycle are stopped by dunning engine, or by hard decline
+	// if there is no dunning engine. Subscription is not billed.
+	SubscriptionStatusUnpaid   SubscriptionStatus = "unpaid"
+	SubscriptionStatusCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription is a schedule of merchant-initiated charges of customer's stored payment method.
+type Subscription struct {
+	ID         string
+	CustomerID string
+	// Token is a token of customer's stored payment method.
+	Token    string
+	Amount   int64
+	Currency string
+	// Interval between billing cycles. Month interval keeps the day of StartAt, clamped to the last day of month.
+	IntervalMonths int
+	IntervalDays   int
+	StartAt        time.Time
+	// Cycle is a number of the next billing cycle, the first cycle is 0 and is billed at StartAt.
+	Cycle int
+	// Attempt is a number of declined charges of the current cycle.
+	Attempt       int
+	NextBillingAt time.Time
+	Status        SubscriptionStatus
+	// NetworkTransactionID of the initial customer-initiated transaction, all charges are chained to it.
+	NetworkTransactionID string
+
+	LastPaymentID string
+	LastResult    *Result
+	// PendingPaymentID and PendingChargeID are set while charge of the current cycle is pending.
+	// Scheduler gets this charge on the next run instead of creating a new one.
+	PendingPaymentID string
+	PendingChargeID  string
+}
+
+// BillingTime returns time of given billing cycle.
+func (s *Subscription) BillingTime(cycle int) time.Time {
+	t := addMonthsClamped(s.StartAt, s.IntervalMonths*cycle)
+	return t.AddDate(0, 0, s.IntervalDays*cycle)
+}
+
+// addMonthsClamped adds months keeping day of month, e.g. Jan 31 + 1 month is Feb 28 (not Mar 3 as with AddDate).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// SubscriptionStore persists subscriptions between scheduler runs.
+type SubscriptionStore interface {
+	// Due returns active and past due subscriptions with next billing time not after now.
+	Due(ctx context.Context, now time.Time) ([]Subscription, error)
+	// Save creates or updates subscription.
+	Save(ctx context.Context, subscription Subscription) error
+}
+
+// SubscriptionBilling is a result of billing one subscription cycle.
+type SubscriptionBilling struct {
+	SubscriptionID string
+	Cycle          int
+	Attempt        int
+	PaymentID      string
+	Charge         *Charge
+	// Err is set if API call failed. Declined charge is not an error, see Charge.Result.
+	Err error
+}
+
+// SubscriptionScheduler charges due subscriptions as merchant-initiated recurring transactions.
+// Every billing cycle uses deterministic idempotency keys, so running scheduler again after crash or in parallel
+// doesn't charge the customer twice.
+type SubscriptionScheduler struct {
+	API   API
+	Store SubscriptionStore
+	// Dunning schedules retries of declined cycles. If nil, soft declines are retried on every run,
+	// and hard declines (see NormalizeDecline) make subscription unpaid.
+	Dunning *DunningEngine
+}
+
+// NewSubscriptionScheduler creates subscription scheduler. If store is nil, in-memory store is used.
+func NewSubscriptionScheduler(api API, store SubscriptionStore) *SubscriptionScheduler {
+	if store == nil {
+		store = NewMemorySubscriptionStore()
+	}
+	return &SubscriptionScheduler{API: api, Store: store}
+}
+
+// SubscriptionIdempotencyKey returns idempotency key of given step ("payment" or "charge") of subscription cycle.
+// One payment is created per cycle, so attempt is always 0 for payment step. Every charge attempt has own key,
+// otherwise retry of declined charge would return the same declined charge.
+func SubscriptionIdempotencyKey(subscriptionID string, cycle int, attempt int, step string) string {
+	return fmt.Sprintf("%s/%d/%s/%d", subscriptionID, cycle, step, attempt)
+}
+
+// Subscribe saves new subscription. Its first cycle is billed at StartAt by the next RunDue.
+func (s *SubscriptionScheduler) Subscribe(ctx context.Context, subscription Subscription) error {
+	if subscription.IntervalMonths <= 0 && subscription.IntervalDays <= 0 {
+		return errors.Errorf("subscription %s has no billing interval", subscription.ID)
+	}
+	if subscription.NetworkTransactionID == "" {
+		return errors.Errorf("subscription %s has no network transaction ID of initial transaction", subscription.ID)
+	}
+	subscription.Status = SubscriptionStatusActive
+	subscription.NextBillingAt = subscription.BillingTime(subscription.Cycle)
+	return s.Store.Save(ctx, subscription)
+}
+
+// RunDue bills all subscriptions due at given time. Error is returned only if store fails, errors of particular
+// subscriptions are reported in results.
+func (s *SubscriptionScheduler) RunDue(ctx context.Context, now time.Time) ([]SubscriptionBilling, error) {
+	subscriptions, err := s.Store.Due(ctx, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get due subscriptions")
+	}
+
+	results := make([]SubscriptionBilling, 0, len(subscriptions))
+	for i := range subscriptions {
+		result := s.Bill(ctx, &subscriptions[i])
+		if s.Dunning != nil && result.Charge != nil {
+			if err := s.dun(ctx, &subscriptions[i], result, now); err != nil {
+				result.Err = err
+			}
+		}
+		if err := s.Store.Save(ctx, subscriptions[i]); err != nil {
+			return results, errors.Wrapf(err, "failed to save subscription %s", subscriptions[i].ID)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Bill charges current cycle of subscription and updates it: approved charge moves subscription to the next cycle,
+// declined charge makes it past due (or unpaid on hard decline without dunning engine), pending charge leaves it as is and is checked again by the next Bill.
+// Updated subscription is not saved.
+func (s *SubscriptionScheduler) Bill(ctx context.Context, subscription *Subscription) SubscriptionBilling {
+	result := SubscriptionBilling{SubscriptionID: subscription.ID, Cycle: subscription.Cycle, Attempt: subscription.Attempt}
+
+	if subscription.NetworkTransactionID == "" {
+		result.Err = errors.Errorf("subscription %s has no network transaction ID of initial transaction", subscription.ID)
+		return result
+	}
+
+	var charge *Charge
+	if subscription.PendingChargeID != "" {
+		result.PaymentID = subscription.PendingPaymentID
+		var err error
+		charge, err = s.API.Charge().Get(ctx, subscription.PendingPaymentID, subscription.PendingChargeID)
+		if err != nil {
+			result.Err = errors.Wrapf(err, "failed to get pending charge %s", subscription.PendingChargeID)
+			return result
+		}
+	} else {
+		payment, err := s.API.Payment().New(ctx, SubscriptionIdempotencyKey(subscription.ID, subscription.Cycle, 0, "payment"), &PaymentParams{
+			Amount:     subscription.Amount,
+			Currency:   subscription.Currency,
+			CustomerID: subscription.CustomerID,
+		})
+		if err != nil {
+			result.Err = errors.Wrap(err, "failed to create payment")
+			return result
+		}
+		result.PaymentID = payment.ID
+		subscription.LastPaymentID = payment.ID
+
+		charge, err = s.API.Charge().New(ctx, SubscriptionIdempotencyKey(subscription.ID, subscription.Cycle, subscription.Attempt, "charge"), payment.ID, &ChargeParams{
+			PaymentMethod:    PaymentMethodDetails{Type: "tokenized", Token: subscription.Token},
+			StoredCredential: NewMerchantInitiatedStoredCredential(StoredCredentialRecurring, subscription.NetworkTransactionID),
+		}, nil)
+		if err != nil {
+			result.Err = errors.Wrap(err, "failed to create charge")
+			return result
+		}
+	}
+	result.Charge = charge
+	subscription.LastResult = &charge.Result
+
+	if charge.Result.IsPending() {
+		subscription.PendingPaymentID = result.PaymentID
+		subscription.PendingChargeID = charge.ID
+		return result
+	}
+	subscription.PendingPaymentID = ""
+	subscription.PendingChargeID = ""
+
+	switch {
+	case charge.Result.IsApproved():
+		subscription.Status = SubscriptionStatusActive
+		subscription.Cycle++
+		subscription.Attempt = 0
+		subscription.NextBillingAt = subscription.BillingTime(subscription.Cycle)
+	case charge.Result.IsFailed():
+		subscription.Status = SubscriptionStatusPastDue
+		subscription.Attempt++
+		// Without dunning engine hard declines are not retried, networks penalize retries of them.
+		if s.Dunning == nil && NormalizeDecline(charge.Result, charge.ProviderData).Class == DeclineClassHard {
+			subscription.Status = SubscriptionStatusUnpaid
+		}
+	}
+	return result
+}
+
+// dun passes charge result to dunning engine and reschedules or stops billing of the subscription.
+func (s *SubscriptionScheduler) dun(ctx context.Context, subscription *Subscription, result SubscriptionBilling, now time.Time) error {
+	dunningCase, err := s.Dunning.Handle(ctx, fmt.Sprintf("%s/%d", result.SubscriptionID, result.Cycle), result.Charge.Result, result.Charge.ProviderData, now)
+	if err != nil || dunningCase == nil {
+		return err
+	}
+	switch dunningCase.Status {
+	case DunningStatusRetrying:
+		subscription.NextBillingAt = dunningCase.NextRetryAt
+	case DunningStatusExhausted, DunningStatusStopped:
+		subscription.Status = SubscriptionStatusUnpaid
+	}
+	return nil
+}
+
+// MemorySubscriptionStore is in-memory implementation of SubscriptionStore. It doesn't survive process restart
+// and is intended for tests and single-process usage.
+type MemorySubscriptionStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+}
+
+// NewMemorySubscriptionStore creates empty MemorySubscriptionStore.
+func NewMemorySubscriptionStore() *MemorySubscriptionStore {
+	return &MemorySubscriptionStore{subscriptions: map[string]Subscription{}}
+}
+
+// Due implements SubscriptionStore interface.
+func (s *MemorySubscriptionStore) Due(ctx context.Context, now time.Time) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Status != SubscriptionStatusActive && subscription.Status != SubscriptionStatusPastDue {
+			continue
+		}
+		if !subscription.NextBillingAt.After(now) {
+			due = append(due, subscription)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextBillingAt.Before(due[j].NextBillingAt)
+	})
+	return due, nil
+}
+
+// Save implements SubscriptionStore interface.
+func (s *MemorySubscriptionStore) Save(ctx context.Context, subscription Subscription) error {
+	if subscription.ID == "" {
+		return errors.New("subscription ID is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+// Get returns subscription by ID.
+func (s *MemorySubscriptionStore) Get(ctx context.Context, id string) (Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	return subscription, ok
+}
@@ -0,0 +1,210 @@
+package zooz
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStoredCredential_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   *StoredCredential
+		expected []string
+	}{
+		{name: "initial", params: NewInitialStoredCredential(StoredCredentialRecurring)},
+		{name: "merchant initiated", params: NewMerchantInitiatedStoredCredential(StoredCredentialRecurring, "ntid")},
+		{name: "merchant initiated without ntid", params: NewMerchantInitiatedStoredCredential(StoredCredentialRecurring, ""), expected: []string{"network_transaction_id"}},
+		{name: "initial mit", params: &StoredCredential{Usage: StoredCredentialInitial, Initiator: TransactionInitiatorMerchant, Reason: StoredCredentialUnscheduled}, expected: []string{"initiator"}},
+		{name: "empty", params: &StoredCredential{}, expected: []string{"initiator", "usage"}},
+	}
+
+	for _, tt := range tests {
+		if fields := validationFields(t, tt.params.Validate()); !equalFields(fields, tt.expected) {
+			t.Errorf("Invalid fields of %s are %v, expected %v", tt.name, fields, tt.expected)
+		}
+	}
+
+	params := &ChargeParams{
+		PaymentMethod:    PaymentMethodDetails{Type: "tokenized", Token: "token"},
+		StoredCredential: &StoredCredential{Usage: StoredCredentialSubsequent, Initiator: TransactionInitiatorMerchant},
+	}
+	fields := validationFields(t, params.Validate())
+	if !equalFields(fields, []string{"stored_credential.network_transaction_id", "stored_credential.reason"}) {
+		t.Errorf("Invalid fields of charge params are %v", fields)
+	}
+}
+
+func TestSubscription_BillingTime(t *testing.T) {
+	s := &Subscription{StartAt: time.Date(2018, time.January, 31, 10, 0, 0, 0, time.UTC), IntervalMonths: 1}
+
+	expected := []time.Time{
+		time.Date(2018, time.January, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2018, time.February, 28, 10, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2018, time.April, 30, 10, 0, 0, 0, time.UTC),
+		time.Date(2019, time.January, 31, 10, 0, 0, 0, time.UTC),
+	}
+	for i, cycle := range []int{0, 1, 2, 3, 12} {
+		if got := s.BillingTime(cycle); !got.Equal(expected[i]) {
+			t.Errorf("Billing time of cycle %d is %s, expected %s", cycle, got, expected[i])
+		}
+	}
+
+	weekly := &Subscription{StartAt: time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC), IntervalDays: 7}
+	if got := weekly.BillingTime(2); !got.Equal(time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Weekly billing time is %s", got)
+	}
+}
+
+func TestSubscriptionScheduler_RunDue(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMock()
+	m.On("Payment.New", "sub/0/payment/0", &PaymentParams{Amount: 990, Currency: "USD", CustomerID: "customer"}).
+		Return(&Payment{ID: "payment0"}, nil).Once()
+	m.On("Charge.New", "sub/0/charge/0", "payment0", &ChargeParams{
+		PaymentMethod:    PaymentMethodDetails{Type: "tokenized", Token: "token"},
+		StoredCredential: NewMerchantInitiatedStoredCredential(StoredCredentialRecurring, "ntid"),
+	}, (*ClientInfo)(nil)).Return(&Charge{ID: "charge0", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+	m.On("Payment.New", "sub/1/payment/0", MockAnything).Return(&Payment{ID: "payment1"}, nil).Times(2)
+	m.On("Charge.New", "sub/1/charge/0", "payment1", MockAnything, MockAnything).
+		Return(&Charge{ID: "charge1", Result: Result{Status: ResultStatusFailed, Category: ResultCategoryPaymentMethodDeclined, SubCategory: ResultSubCategoryInsufficientFunds}}, nil).Once()
+	m.On("Charge.New", "sub/1/charge/1", "payment1", MockAnything, MockAnything).
+		Return(&Charge{ID: "charge2", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	store := NewMemorySubscriptionStore()
+	s := NewSubscriptionScheduler(m, store)
+	err := s.Subscribe(ctx, Subscription{
+		ID:                   "sub",
+		CustomerID:           "customer",
+		Token:                "token",
+		Amount:               990,
+		Currency:             "USD",
+		IntervalMonths:       1,
+		StartAt:              start,
+		NetworkTransactionID: "ntid",
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	if results, err := s.RunDue(ctx, start.Add(-time.Hour)); err != nil || len(results) != 0 {
+		t.Fatalf("Nothing must be billed before start: %+v, %v", results, err)
+	}
+
+	results, err := s.RunDue(ctx, start)
+	if err != nil || len(results) != 1 || results[0].Charge == nil || results[0].Charge.ID != "charge0" {
+		t.Fatalf("First cycle result is not as expected: %+v, %v", results, err)
+	}
+	sub, _ := store.Get(ctx, "sub")
+	if sub.Cycle != 1 || sub.Status != SubscriptionStatusActive || !sub.NextBillingAt.Equal(start.AddDate(0, 1, 0)) {
+		t.Errorf("Subscription after first cycle is not as expected: %+v", sub)
+	}
+
+	results, err = s.RunDue(ctx, start.AddDate(0, 1, 0))
+	if err != nil || len(results) != 1 || results[0].Charge.ID != "charge1" {
+		t.Fatalf("Second cycle result is not as expected: %+v, %v", results, err)
+	}
+	sub, _ = store.Get(ctx, "sub")
+	if sub.Cycle != 1 || sub.Attempt != 1 || sub.Status != SubscriptionStatusPastDue || sub.LastPaymentID != "payment1" {
+		t.Errorf("Subscription after decline is not as expected: %+v", sub)
+	}
+
+	results, err = s.RunDue(ctx, start.AddDate(0, 1, 1))
+	if err != nil || len(results) != 1 || results[0].Charge.ID != "charge2" || results[0].Attempt != 1 {
+		t.Fatalf("Retry result is not as expected: %+v, %v", results, err)
+	}
+	sub, _ = store.Get(ctx, "sub")
+	if sub.Cycle != 2 || sub.Attempt != 0 || sub.Status != SubscriptionStatusActive {
+		t.Errorf("Subscription after retry is not as expected: %+v", sub)
+	}
+
+	m.AssertExpectations(t)
+}
+
+func TestSubscriptionScheduler_RunDue_Pending(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMock()
+	m.On("Payment.New", "sub/0/payment/0", MockAnything).Return(&Payment{ID: "payment0"}, nil).Once()
+	m.On("Charge.New", "sub/0/charge/0", "payment0", MockAnything, MockAnything).
+		Return(&Charge{ID: "charge0", Result: Result{Status: ResultStatusPending}}, nil).Once()
+	m.On("Charge.Get", "payment0", "charge0").Return(&Charge{ID: "charge0", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	store := NewMemorySubscriptionStore()
+	s := NewSubscriptionScheduler(m, store)
+	err := s.Subscribe(ctx, Subscription{ID: "sub", Token: "token", Amount: 990, Currency: "USD", IntervalMonths: 1, StartAt: start, NetworkTransactionID: "ntid"})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	results, err := s.RunDue(ctx, start)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Pending cycle result is not as expected: %+v, %v", results, err)
+	}
+	sub, _ := store.Get(ctx, "sub")
+	if sub.Cycle != 0 || sub.Attempt != 0 || sub.PendingPaymentID != "payment0" || sub.PendingChargeID != "charge0" {
+		t.Errorf("Subscription after pending charge is not as expected: %+v", sub)
+	}
+
+	results, err = s.RunDue(ctx, start.Add(time.Hour))
+	if err != nil || len(results) != 1 || results[0].Charge == nil || results[0].PaymentID != "payment0" || !results[0].Charge.Result.IsApproved() {
+		t.Fatalf("Pending charge result is not as expected: %+v, %v", results, err)
+	}
+	sub, _ = store.Get(ctx, "sub")
+	if sub.Cycle != 1 || sub.Status != SubscriptionStatusActive || sub.PendingChargeID != "" || sub.PendingPaymentID != "" {
+		t.Errorf("Subscription after approved charge is not as expected: %+v", sub)
+	}
+
+	m.AssertExpectations(t)
+}
+
+func TestSubscriptionScheduler_RunDue_HardDecline(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMock()
+	m.On("Payment.New").Return(&Payment{ID: "payment0"}, nil).Once()
+	m.On("Charge.New").Return(&Charge{ID: "charge0", Result: Result{
+		Status:      ResultStatusFailed,
+		Category:    ResultCategoryPaymentMethodDeclined,
+		SubCategory: ResultSubCategoryLostOrStolen,
+	}}, nil).Once()
+
+	store := NewMemorySubscriptionStore()
+	s := NewSubscriptionScheduler(m, store)
+	err := s.Subscribe(ctx, Subscription{ID: "sub", Token: "token", Amount: 990, Currency: "USD", IntervalMonths: 1, StartAt: start, NetworkTransactionID: "ntid"})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	if _, err := s.RunDue(ctx, start); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	sub, _ := store.Get(ctx, "sub")
+	if sub.Status != SubscriptionStatusUnpaid {
+		t.Errorf("Subscription after hard decline is not as expected: %+v", sub)
+	}
+
+	if results, err := s.RunDue(ctx, start.AddDate(0, 0, 1)); err != nil || len(results) != 0 {
+		t.Errorf("Unpaid subscription must not be billed: %+v, %v", results, err)
+	}
+	m.AssertExpectations(t)
+}
+
+func TestSubscriptionScheduler_Subscribe(t *testing.T) {
+	s := NewSubscriptionScheduler(NewMock(), nil)
+	if err := s.Subscribe(context.Background(), Subscription{ID: "sub"}); err == nil {
+		t.Errorf("Error expected for subscription without interval")
+	}
+	if err := s.Subscribe(context.Background(), Subscription{ID: "sub", IntervalMonths: 1}); err == nil {
+		t.Errorf("Error expected for subscription without network transaction ID")
+	}
+
+	if result := s.Bill(context.Background(), &Subscription{ID: "sub", IntervalMonths: 1}); result.Err == nil {
+		t.Errorf("Error expected for billing without network transaction ID")
+	}
+}
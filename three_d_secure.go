@@ -0,0 +1,270 @@
+package zooz
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ThreeDSecureStatus is a type of 3-D Secure 2 authentication status (transStatus of EMV 3DS specification).
+type ThreeDSecureStatus string
+
+// List of possible 3-D Secure 2 authentication statuses.
+const (
+	// ThreeDSecureStatusAuthenticated means successful frictionless or challenge authentication.
+	ThreeDSecureStatusAuthenticated ThreeDSecureStatus = "Y"
+	// ThreeDSecureStatusAttempted means authentication was attempted, but issuer or card doesn't support it.
+	ThreeDSecureStatusAttempted ThreeDSecureStatus = "A"
+	// ThreeDSecureStatusChallengeRequired means cardholder must complete challenge.
+	ThreeDSecureStatusChallengeRequired ThreeDSecureStatus = "C"
+	// ThreeDSecureStatusDecoupled means cardholder is authenticated outside of the checkout.
+	ThreeDSecureStatusDecoupled   ThreeDSecureStatus = "D"
+	ThreeDSecureStatusFailed      ThreeDSecureStatus = "N"
+	ThreeDSecureStatusUnavailable ThreeDSecureStatus = "U"
+	ThreeDSecureStatusRejected    ThreeDSecureStatus = "R"
+)
+
+// IsAuthenticated reports whether status gives liability shift.
+func (s ThreeDSecureStatus) IsAuthenticated() bool {
+	return s == ThreeDSecureStatusAuthenticated || s == ThreeDSecureStatusAttempted
+}
+
+// ThreeDSecureExternal is a set of results of 3-D Secure 2 authentication performed by merchant's own 3DS server.
+type ThreeDSecureExternal struct {
+	Version              string             `json:"three_d_secure_version"`
+	AuthenticationStatus ThreeDSecureStatus `json:"three_d_secure_authentication_status,omitempty"`
+	// DSTransactionID is a directory server transaction ID.
+	DSTransactionID string `json:"ds_xid,omitempty"`
+	// AuthenticationValue is CAVV for Visa or AAV for Mastercard.
+	AuthenticationValue string `json:"cavv,omitempty"`
+	EciFlag             string `json:"eci_flag,omitempty"`
+	XID                 string `json:"xid,omitempty"`
+}
+
+// ThreeDSecureDeviceChannel is a type of channel the cardholder is authenticated in.
+type ThreeDSecureDeviceChannel string
+
+// List of possible device channels.
+const (
+	ThreeDSecureDeviceChannelBrowser ThreeDSecureDeviceChannel = "browser"
+	ThreeDSecureDeviceChannelApp     ThreeDSecureDeviceChannel = "app"
+)
+
+// ThreeDSecureChallengeIndicator is a merchant preference for challenge.
+type ThreeDSecureChallengeIndicator string
+
+// List of possible challenge indicators.
+const (
+	ThreeDSecureChallengeNoPreference ThreeDSecureChallengeIndicator = "no_preference"
+	ThreeDSecureChallengeNotRequested ThreeDSecureChallengeIndicator = "no_challenge_requested"
+	ThreeDSecureChallengeRequested    ThreeDSecureChallengeIndicator = "challenge_requested"
+	ThreeDSecureChallengeMandated     ThreeDSecureChallengeIndicator = "challenge_mandated"
+)
+
+// ThreeDSecureInternal is a set of params for 3-D Secure 2 authentication run by provider.
+// Browser info is required for browser channel.
+type ThreeDSecureInternal struct {
+	DeviceChannel      ThreeDSecureDeviceChannel      `json:"device_channel,omitempty"`
+	ChallengeIndicator ThreeDSecureChallengeIndicator `json:"challenge_indicator,omitempty"`
+	BrowserInfo        *BrowserInfo                   `json:"browser_info,omitempty"`
+}
+
+// BrowserInfo is a set of cardholder browser properties, used by issuer for risk-based authentication.
+// Accept header, user agent and language are taken from request headers, other fields are collected by
+// JavaScript on checkout page.
+type BrowserInfo struct {
+	AcceptHeader      string `json:"accept_header"`
+	UserAgent         string `json:"user_agent"`
+	Language          string `json:"language"`
+	IPAddress         string `json:"ip_address,omitempty"`
+	JavaEnabled       bool   `json:"java_enabled"`
+	JavascriptEnabled bool   `json:"javascript_enabled"`
+	ColorDepth        int    `json:"color_depth,omitempty"`
+	ScreenHeight      int    `json:"screen_height,omitempty"`
+	ScreenWidth       int    `json:"screen_width,omitempty"`
+	// TimeZoneOffset is a difference between UTC and cardholder browser local time in minutes,
+	// as returned by Date.getTimezoneOffset().
+	TimeZoneOffset int `json:"time_zone_offset"`
+}
+
+// Names of form values NewBrowserInfo reads properties collected by JavaScript from.
+const (
+	BrowserInfoFormJavaEnabled       = "browser_java_enabled"
+	BrowserInfoFormJavascriptEnabled = "browser_javascript_enabled"
+	BrowserInfoFormColorDepth        = "browser_color_depth"
+	BrowserInfoFormScreenHeight      = "browser_screen_height"
+	BrowserInfoFormScreenWidth       = "browser_screen_width"
+	BrowserInfoFormTimeZoneOffset    = "browser_time_zone_offset"
+)
+
+// NewBrowserInfo collects browser info from checkout request: headers and form values named BrowserInfoForm*.
+// If JavaScript form values are absent, JavascriptEnabled is false.
+func NewBrowserInfo(r *http.Request) (*BrowserInfo, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, errors.Wrap(err, "failed to parse form")
+	}
+
+	info := &BrowserInfo{
+		AcceptHeader: r.Header.Get("Accept"),
+		UserAgent:    r.UserAgent(),
+		Language:     strings.TrimSpace(strings.Split(strings.Split(r.Header.Get("Accept-Language"), ",")[0], ";")[0]),
+	}
+	if host := r.RemoteAddr; host != "" {
+		if i := strings.LastIndex(host, ":"); i > 0 {
+			host = host[:i]
+		}
+		info.IPAddress = strings.Trim(host, "[]")
+	}
+
+	if r.Form.Get(BrowserInfoFormJavascriptEnabled) == "" {
+		return info, nil
+	}
+
+	var err error
+	if info.JavascriptEnabled, err = strconv.ParseBool(r.Form.Get(BrowserInfoFormJavascriptEnabled)); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", BrowserInfoFormJavascriptEnabled)
+	}
+	if value := r.Form.Get(BrowserInfoFormJavaEnabled); value != "" {
+		if info.JavaEnabled, err = strconv.ParseBool(value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", BrowserInfoFormJavaEnabled)
+		}
+	}
+	for name, field := range map[string]*int{
+		BrowserInfoFormColorDepth:     &info.ColorDepth,
+		BrowserInfoFormScreenHeight:   &info.ScreenHeight,
+		BrowserInfoFormScreenWidth:    &info.ScreenWidth,
+		BrowserInfoFormTimeZoneOffset: &info.TimeZoneOffset,
+	} {
+		if value := r.Form.Get(name); value != "" {
+			if *field, err = strconv.Atoi(value); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s", name)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// Validate checks browser info required by 3-D Secure 2 and returns ValidationErrors if some fields are invalid.
+func (b *BrowserInfo) Validate() error {
+	var v validation
+	if b.AcceptHeader == "" {
+		v.add("accept_header", "is required")
+	}
+	if b.UserAgent == "" {
+		v.add("user_agent", "is required")
+	}
+	if b.Language == "" {
+		v.add("language", "is required")
+	}
+	if b.JavascriptEnabled {
+		if b.ColorDepth <= 0 {
+			v.add("color_depth", "is required when JavaScript is enabled")
+		}
+		if b.ScreenHeight <= 0 {
+			v.add("screen_height", "is required when JavaScript is enabled")
+		}
+		if b.ScreenWidth <= 0 {
+			v.add("screen_width", "is required when JavaScript is enabled")
+		}
+	}
+	return v.err()
+}
+
+// Validate checks 3-D Secure attributes and returns ValidationErrors if some fields are invalid.
+func (a *ThreeDSecureAttributes) Validate() error {
+	var v validation
+	if a.External != nil && a.Internal != nil {
+		v.add("external", "must not be set together with internal")
+	}
+	if e := a.External; e != nil {
+		if !strings.HasPrefix(e.Version, "2.") {
+			v.add("external.three_d_secure_version", "%q is not 3-D Secure 2 version", e.Version)
+		}
+		if e.AuthenticationStatus.IsAuthenticated() && e.AuthenticationValue == "" {
+			v.add("external.cavv", "is required for authenticated status")
+		}
+		if e.DSTransactionID == "" {
+			v.add("external.ds_xid", "is required")
+		}
+	}
+	if i := a.Internal; i != nil {
+		channel := i.DeviceChannel
+		if channel == "" {
+			channel = ThreeDSecureDeviceChannelBrowser
+		}
+		if channel == ThreeDSecureDeviceChannelBrowser {
+			if i.BrowserInfo == nil {
+				v.add("internal.browser_info", "is required for browser channel")
+			} else {
+				v.nested("internal.browser_info", i.BrowserInfo.Validate())
+			}
+		}
+	}
+	return v.err()
+}
+
+// ThreeDSecureFlow is a type of 3-D Secure authentication flow, transaction went through.
+type ThreeDSecureFlow string
+
+// List of possible 3-D Secure flows.
+const (
+	// ThreeDSecureFlowNone means 3-D Secure was not performed.
+	ThreeDSecureFlowNone ThreeDSecureFlow = "none"
+	// ThreeDSecureFlowFrictionless means cardholder was authenticated without interaction.
+	ThreeDSecureFlowFrictionless ThreeDSecureFlow = "frictionless"
+	// ThreeDSecureFlowChallenge means cardholder must be redirected to ChallengeURL to complete authentication.
+	ThreeDSecureFlowChallenge ThreeDSecureFlow = "challenge"
+	// ThreeDSecureFlowFailed means authentication failed or was rejected.
+	ThreeDSecureFlowFailed ThreeDSecureFlow = "failed"
+)
+
+// ThreeDSecureDecision describes what to do next with transaction according to its 3-D Secure state.
+type ThreeDSecureDecision struct {
+	Flow ThreeDSecureFlow
+	// ChallengeURL is set for challenge flow. Redirect the cardholder there, they will return to merchant site URL.
+	ChallengeURL         string
+	Version              string
+	AuthenticationStatus ThreeDSecureStatus
+	EciFlag              string
+}
+
+// ThreeDSecure returns 3-D Secure decision for authorization.
+func (a *Authorization) ThreeDSecure() ThreeDSecureDecision {
+	return newThreeDSecureDecision(a.Result, a.ThreeDSecureAttributes, a.Redirection)
+}
+
+// ThreeDSecure returns 3-D Secure decision for charge.
+func (c *Charge) ThreeDSecure() ThreeDSecureDecision {
+	return newThreeDSecureDecision(c.Result, c.ThreeDSecureAttributes, c.Redirection)
+}
+
+func newThreeDSecureDecision(result Result, attributes *ThreeDSecureAttributes, redirection *Redirection) ThreeDSecureDecision {
+	decision := ThreeDSecureDecision{Flow: ThreeDSecureFlowNone}
+	if attributes != nil {
+		decision.EciFlag = attributes.EciFlag
+		if e := attributes.External; e != nil {
+			decision.Version = e.Version
+			decision.AuthenticationStatus = e.AuthenticationStatus
+			if e.EciFlag != "" {
+				decision.EciFlag = e.EciFlag
+			}
+		}
+	}
+
+	switch {
+	case result.Category == ResultCategoryThreeDSecureFailed,
+		decision.AuthenticationStatus == ThreeDSecureStatusFailed,
+		decision.AuthenticationStatus == ThreeDSecureStatusRejected:
+		decision.Flow = ThreeDSecureFlowFailed
+	case result.IsPending() && redirection != nil && redirection.URL != "":
+		decision.Flow = ThreeDSecureFlowChallenge
+		decision.ChallengeURL = redirection.URL
+	case decision.AuthenticationStatus.IsAuthenticated(),
+		decision.AuthenticationStatus == "" && attributes != nil && (attributes.CAVV != "" || attributes.External != nil):
+		decision.Flow = ThreeDSecureFlowFrictionless
+	}
+	return decision
+}
@@ -0,0 +1,186 @@
+package zooz
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestThreeDSecureAttributes_MarshalJSON(t *testing.T) {
+	params := &AuthorizationParams{
+		PaymentMethod: PaymentMethodDetails{Type: "tokenized", Token: "token"},
+		ThreeDSecureAttributes: &ThreeDSecureAttributes{
+			External: &ThreeDSecureExternal{
+				Version:              "2.1.0",
+				AuthenticationStatus: ThreeDSecureStatusAuthenticated,
+				DSTransactionID:      "ds_xid",
+				AuthenticationValue:  "cavv",
+				EciFlag:              "05",
+			},
+		},
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	expected := `"three_d_secure_attributes":{"external":{"three_d_secure_version":"2.1.0","three_d_secure_authentication_status":"Y","ds_xid":"ds_xid","cavv":"cavv","eci_flag":"05"}}`
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("JSON is not as expected: %s", data)
+	}
+	if err := params.Validate(); err != nil {
+		t.Errorf("Params must be valid: %s", err)
+	}
+
+	data, err = json.Marshal(&ThreeDSecureAttributes{CAVV: "cavv"})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if expected := `{"encoding":"","xid":"","cavv":"cavv","eci_flag":""}`; string(data) != expected {
+		t.Errorf("3-D Secure 1 JSON is %s, expected %s", data, expected)
+	}
+}
+
+func TestThreeDSecureAttributes_Validate(t *testing.T) {
+	params := &ChargeParams{
+		PaymentMethod: PaymentMethodDetails{Type: "tokenized", Token: "token"},
+		ThreeDSecureAttributes: &ThreeDSecureAttributes{
+			Internal: &ThreeDSecureInternal{
+				BrowserInfo: &BrowserInfo{UserAgent: "Mozilla/5.0", JavascriptEnabled: true, ScreenWidth: 1920},
+			},
+		},
+	}
+
+	fields := validationFields(t, params.Validate())
+	expected := []string{
+		"merchant_site_url",
+		"three_d_secure_attributes.internal.browser_info.accept_header",
+		"three_d_secure_attributes.internal.browser_info.color_depth",
+		"three_d_secure_attributes.internal.browser_info.language",
+		"three_d_secure_attributes.internal.browser_info.screen_height",
+	}
+	if !equalFields(fields, expected) {
+		t.Errorf("Invalid fields are %v, expected %v", fields, expected)
+	}
+
+	external := &ThreeDSecureAttributes{External: &ThreeDSecureExternal{Version: "1.0.2", AuthenticationStatus: ThreeDSecureStatusAttempted}}
+	fields = validationFields(t, external.Validate())
+	expected = []string{"external.cavv", "external.ds_xid", "external.three_d_secure_version"}
+	if !equalFields(fields, expected) {
+		t.Errorf("Invalid fields are %v, expected %v", fields, expected)
+	}
+}
+
+func TestNewBrowserInfo(t *testing.T) {
+	form := url.Values{
+		BrowserInfoFormJavascriptEnabled: {"true"},
+		BrowserInfoFormJavaEnabled:       {"false"},
+		BrowserInfoFormColorDepth:        {"24"},
+		BrowserInfoFormScreenHeight:      {"1080"},
+		BrowserInfoFormScreenWidth:       {"1920"},
+		BrowserInfoFormTimeZoneOffset:    {"-180"},
+	}
+	r := httptest.NewRequest("POST", "/checkout", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "text/html")
+	r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	r.Header.Set("User-Agent", "Mozilla/5.0")
+	r.RemoteAddr = "203.0.113.10:51234"
+
+	info, err := NewBrowserInfo(r)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	expected := BrowserInfo{
+		AcceptHeader:      "text/html",
+		UserAgent:         "Mozilla/5.0",
+		Language:          "en-US",
+		IPAddress:         "203.0.113.10",
+		JavascriptEnabled: true,
+		ColorDepth:        24,
+		ScreenHeight:      1080,
+		ScreenWidth:       1920,
+		TimeZoneOffset:    -180,
+	}
+	if *info != expected {
+		t.Errorf("Browser info is %+v, expected %+v", *info, expected)
+	}
+	if err := info.Validate(); err != nil {
+		t.Errorf("Browser info must be valid: %s", err)
+	}
+
+	r = httptest.NewRequest("POST", "/checkout?"+BrowserInfoFormJavascriptEnabled+"=true&"+BrowserInfoFormColorDepth+"=deep", nil)
+	if _, err := NewBrowserInfo(r); err == nil {
+		t.Errorf("Error expected for invalid color depth")
+	}
+}
+
+func TestAuthorization_ThreeDSecure(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization Authorization
+		expected      ThreeDSecureFlow
+		challengeURL  string
+	}{
+		{
+			name:          "no 3ds",
+			authorization: Authorization{Result: Result{Status: ResultStatusSucceed}},
+			expected:      ThreeDSecureFlowNone,
+		},
+		{
+			name: "frictionless",
+			authorization: Authorization{
+				Result:                 Result{Status: ResultStatusSucceed},
+				ThreeDSecureAttributes: &ThreeDSecureAttributes{External: &ThreeDSecureExternal{Version: "2.2.0", AuthenticationStatus: ThreeDSecureStatusAuthenticated}},
+			},
+			expected: ThreeDSecureFlowFrictionless,
+		},
+		{
+			name: "challenge",
+			authorization: Authorization{
+				Result:      Result{Status: ResultStatusPending},
+				Redirection: &Redirection{ID: "redirection", URL: "https://acs.example.com/challenge"},
+			},
+			expected:     ThreeDSecureFlowChallenge,
+			challengeURL: "https://acs.example.com/challenge",
+		},
+		{
+			name: "failed category",
+			authorization: Authorization{
+				Result: Result{Status: ResultStatusFailed, Category: ResultCategoryThreeDSecureFailed},
+			},
+			expected: ThreeDSecureFlowFailed,
+		},
+		{
+			name: "rejected",
+			authorization: Authorization{
+				Result:                 Result{Status: ResultStatusFailed},
+				ThreeDSecureAttributes: &ThreeDSecureAttributes{External: &ThreeDSecureExternal{Version: "2.1.0", AuthenticationStatus: ThreeDSecureStatusRejected}},
+			},
+			expected: ThreeDSecureFlowFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		decision := tt.authorization.ThreeDSecure()
+		if decision.Flow != tt.expected || decision.ChallengeURL != tt.challengeURL {
+			t.Errorf("Decision for %s is %+v, expected %s", tt.name, decision, tt.expected)
+		}
+	}
+}
+
+func TestCharge_ThreeDSecure(t *testing.T) {
+	var charge Charge
+	data := `{"id":"charge","result":{"status":"Pending"},"three_d_secure_attributes":{"external":{"three_d_secure_version":"2.1.0","three_d_secure_authentication_status":"C","ds_xid":"ds"}},"redirection":{"id":"redirection","url":"https://acs.example.com"}}`
+	if err := json.Unmarshal([]byte(data), &charge); err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	decision := charge.ThreeDSecure()
+	if decision.Flow != ThreeDSecureFlowChallenge || decision.ChallengeURL != "https://acs.example.com" ||
+		decision.Version != "2.1.0" || decision.AuthenticationStatus != ThreeDSecureStatusChallengeRequired {
+		t.Errorf("Decision is not as expected: %+v", decision)
+	}
+}
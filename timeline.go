@@ -0,0 +1,189 @@
+package zooz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimelineEventType is a type of payment timeline event.
+type TimelineEventType string
+
+// List of possible timeline event types.
+const (
+	TimelineEventPayment       TimelineEventType = "payment"
+	TimelineEventAuthorization TimelineEventType = "authorization"
+	TimelineEventCharge        TimelineEventType = "charge"
+	TimelineEventRedirection   TimelineEventType = "redirection"
+	TimelineEventCapture       TimelineEventType = "capture"
+	TimelineEventVoid          TimelineEventType = "void"
+	TimelineEventRefund        TimelineEventType = "refund"
+	TimelineEventCredit        TimelineEventType = "credit"
+)
+
+// timelineEventOrder orders events created at the same time in natural order of payment flow.
+var timelineEventOrder = map[TimelineEventType]int{
+	TimelineEventPayment:       0,
+	TimelineEventAuthorization: 1,
+	TimelineEventCharge:        1,
+	TimelineEventRedirection:   2,
+	TimelineEventCapture:       3,
+	TimelineEventVoid:          3,
+	TimelineEventRefund:        4,
+	TimelineEventCredit:        4,
+}
+
+// TimelineEvent is one event of payment timeline.
+type TimelineEvent struct {
+	Time         Timestamp         `json:"time"`
+	Type         TimelineEventType `json:"type"`
+	ID           string            `json:"id"`
+	Amount       int64             `json:"amount,omitempty"`
+	Result       *Result           `json:"result,omitempty"`
+	ProviderData *ProviderData     `json:"provider_data,omitempty"`
+	// CaptureID is set for refunds of particular capture.
+	CaptureID string `json:"capture_id,omitempty"`
+	// URL is set for redirections.
+	URL string `json:"url,omitempty"`
+}
+
+// PaymentTimeline is a chronological view of everything that happened to a payment.
+// It is marshaled to JSON as is, and WriteText renders it as a table for admin tools.
+type PaymentTimeline struct {
+	PaymentID string          `json:"payment_id"`
+	Status    PaymentStatus   `json:"status"`
+	Amount    int64           `json:"amount"`
+	Currency  string          `json:"currency"`
+	Events    []TimelineEvent `json:"events"`
+}
+
+// NewPaymentTimeline builds timeline of payment. Payment must be requested with all expansions
+// (PaymentExpandAll), otherwise timeline contains only payment creation.
+func NewPaymentTimeline(payment *Payment) (*PaymentTimeline, error) {
+	if payment == nil {
+		return nil, errors.New("payment is nil")
+	}
+
+	t := &PaymentTimeline{
+		PaymentID: payment.ID,
+		Status:    payment.Status,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Events: []TimelineEvent{{
+			Time:   payment.Created,
+			Type:   TimelineEventPayment,
+			ID:     payment.ID,
+			Amount: payment.Amount,
+		}},
+	}
+
+	if resources := payment.RelatedResources; resources != nil {
+		for i := range resources.Authorizations {
+			a := &resources.Authorizations[i]
+			t.add(TimelineEvent{Time: a.Created, Type: TimelineEventAuthorization, ID: a.ID, Amount: a.Amount, Result: &a.Result, ProviderData: &a.ProviderData})
+			if a.Redirection != nil {
+				t.addRedirection(a.Redirection)
+			}
+		}
+		for i := range resources.Charges {
+			c := &resources.Charges[i]
+			t.add(TimelineEvent{Time: c.Created, Type: TimelineEventCharge, ID: c.ID, Amount: c.Amount, Result: &c.Result, ProviderData: &c.ProviderData})
+			if c.Redirection != nil {
+				t.addRedirection(c.Redirection)
+			}
+		}
+		for i := range resources.Redirections {
+			t.addRedirection(&resources.Redirections[i])
+		}
+		for i := range resources.Captures {
+			c := &resources.Captures[i]
+			t.add(TimelineEvent{Time: c.Created, Type: TimelineEventCapture, ID: c.ID, Amount: c.Amount, Result: &c.Result, ProviderData: &c.ProviderData})
+		}
+		for i := range resources.Voids {
+			v := &resources.Voids[i]
+			t.add(TimelineEvent{Time: v.Created, Type: TimelineEventVoid, ID: v.ID, Result: &v.Result, ProviderData: &v.ProviderData})
+		}
+		for i := range resources.Refunds {
+			r := &resources.Refunds[i]
+			t.add(TimelineEvent{Time: r.Created, Type: TimelineEventRefund, ID: r.ID, Amount: r.Amount, Result: &r.Result, ProviderData: &r.ProviderData, CaptureID: r.CaptureID})
+		}
+		for i := range resources.Credits {
+			c := &resources.Credits[i]
+			t.add(TimelineEvent{Time: c.Created, Type: TimelineEventCredit, ID: c.ID, Amount: c.Amount, Result: &c.Result, ProviderData: &c.ProviderData})
+		}
+	}
+
+	sort.SliceStable(t.Events, func(i, j int) bool {
+		if !t.Events[i].Time.Equal(t.Events[j].Time) {
+			return t.Events[i].Time.Before(t.Events[j].Time)
+		}
+		return timelineEventOrder[t.Events[i].Type] < timelineEventOrder[t.Events[j].Type]
+	})
+
+	return t, nil
+}
+
+func (t *PaymentTimeline) add(event TimelineEvent) {
+	t.Events = append(t.Events, event)
+}
+
+// addRedirection adds redirection once, because it may be returned both in transaction and in expansion.
+func (t *PaymentTimeline) addRedirection(redirection *Redirection) {
+	for _, event := range t.Events {
+		if event.Type == TimelineEventRedirection && event.ID == redirection.ID {
+			return
+		}
+	}
+	t.add(TimelineEvent{Time: redirection.Created, Type: TimelineEventRedirection, ID: redirection.ID, URL: redirection.URL})
+}
+
+// WriteText renders timeline as a text table.
+func (t *PaymentTimeline) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Payment %s: %s, %d %s\n", t.PaymentID, t.Status, t.Amount, t.Currency); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, event := range t.Events {
+		var when, amount, status, details string
+		if !event.Time.IsZero() {
+			when = event.Time.Time().Format(time.RFC3339)
+		}
+		if event.Amount != 0 {
+			amount = fmt.Sprintf("%d %s", event.Amount, t.Currency)
+		}
+		if event.Result != nil {
+			status = string(event.Result.Status)
+			if event.Result.Category != "" {
+				status += " (" + string(event.Result.Category) + ")"
+			}
+		}
+		switch {
+		case event.ProviderData != nil && event.ProviderData.ProviderName != "":
+			details = fmt.Sprintf("%s code=%s %s", event.ProviderData.ProviderName, event.ProviderData.ResponseCode, event.ProviderData.Description)
+		case event.URL != "":
+			details = event.URL
+		}
+		if event.CaptureID != "" {
+			details += " capture=" + event.CaptureID
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", when, event.Type, event.ID, amount, status, details); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// String implements stringer interface.
+func (t *PaymentTimeline) String() string {
+	var buf bytes.Buffer
+	if err := t.WriteText(&buf); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
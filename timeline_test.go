@@ -0,0 +1,81 @@
+package zooz
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNewPaymentTimeline(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/payment.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %s", err)
+	}
+	var payment Payment
+	if err := json.Unmarshal(data, &payment); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+
+	timeline, err := NewPaymentTimeline(&payment)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+
+	expected := []TimelineEventType{
+		TimelineEventPayment,
+		TimelineEventAuthorization,
+		TimelineEventCharge,
+		TimelineEventRedirection,
+		TimelineEventCapture,
+		TimelineEventVoid,
+		TimelineEventRefund,
+		TimelineEventCredit,
+	}
+	if len(timeline.Events) != len(expected) {
+		t.Fatalf("Invalid events count: %+v", timeline.Events)
+	}
+	for i, event := range timeline.Events {
+		if event.Type != expected[i] {
+			t.Errorf("Invalid event %d type: %s", i, event.Type)
+		}
+	}
+
+	refund := timeline.Events[6]
+	if refund.Amount != 1000 || refund.CaptureID == "" || refund.Result.Status != ResultStatusSucceed || refund.ProviderData.ProviderName != "Stripe" {
+		t.Errorf("Refund event is not as expected: %+v", refund)
+	}
+
+	text := timeline.String()
+	if !strings.Contains(text, "2017-12-29T12:23:20Z  authorization") {
+		t.Errorf("Invalid text:\n%s", text)
+	}
+	if !strings.Contains(text, "Failed (payment_method_declined)") {
+		t.Errorf("Invalid text:\n%s", text)
+	}
+
+	encoded, err := json.Marshal(timeline)
+	if err != nil {
+		t.Fatalf("Marshal error: %s", err)
+	}
+	if !strings.Contains(string(encoded), `"type":"credit"`) {
+		t.Errorf("Invalid JSON: %s", encoded)
+	}
+}
+
+func TestNewPaymentTimeline_SameTime(t *testing.T) {
+	timeline, err := NewPaymentTimeline(&Payment{
+		ID:      "id",
+		Created: TimestampFromMillis(1),
+		RelatedResources: &PaymentRelatedResources{
+			Captures:       []Capture{{ID: "capture", Created: TimestampFromMillis(1)}},
+			Authorizations: []Authorization{{ID: "authorization", Created: TimestampFromMillis(1)}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if timeline.Events[0].ID != "id" || timeline.Events[1].ID != "authorization" || timeline.Events[2].ID != "capture" {
+		t.Errorf("Events are not ordered by flow: %+v", timeline.Events)
+	}
+}
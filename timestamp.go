@@ -0,0 +1,139 @@
+package zooz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Timestamp is a time of entity creation or modification.
+// API returns timestamps as epoch milliseconds, either as a number or as a string (e.g. "1514550000000"),
+// and some resources use RFC 3339 strings. Timestamp accepts all these formats and marshals back to the same
+// representation it was decoded from.
+//
+// Timestamp fields were json.Number before, so String, Int64 and Float64 methods behave as json.Number ones
+// to keep existing code working, and zero Timestamp is marshaled as 0 like empty json.Number.
+type Timestamp struct {
+	time time.Time
+	raw  string
+}
+
+// NewTimestamp creates Timestamp from given time. It is marshaled as epoch milliseconds number.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{time: t}
+}
+
+// TimestampFromMillis creates Timestamp from epoch milliseconds.
+func TimestampFromMillis(ms int64) Timestamp {
+	return Timestamp{time: time.Unix(0, ms*int64(time.Millisecond)).UTC()}
+}
+
+// Time returns timestamp as time.Time in UTC.
+func (t Timestamp) Time() time.Time {
+	return t.time
+}
+
+// IsZero reports whether timestamp is not set.
+func (t Timestamp) IsZero() bool {
+	return t.time.IsZero()
+}
+
+// Millis returns timestamp as epoch milliseconds.
+func (t Timestamp) Millis() int64 {
+	if t.time.IsZero() {
+		return 0
+	}
+	return t.time.UnixNano() / int64(time.Millisecond)
+}
+
+// Before reports whether timestamp is before u.
+func (t Timestamp) Before(u Timestamp) bool {
+	return t.time.Before(u.time)
+}
+
+// After reports whether timestamp is after u.
+func (t Timestamp) After(u Timestamp) bool {
+	return t.time.After(u.time)
+}
+
+// Equal reports whether timestamps represent the same time instant regardless of their JSON representation.
+func (t Timestamp) Equal(u Timestamp) bool {
+	return t.time.Equal(u.time)
+}
+
+// Compare returns -1 if timestamp is before u, +1 if it is after u and 0 if they are equal.
+func (t Timestamp) Compare(u Timestamp) int {
+	switch {
+	case t.time.Before(u.time):
+		return -1
+	case t.time.After(u.time):
+		return 1
+	}
+	return 0
+}
+
+// String returns epoch milliseconds as string, as json.Number did. Empty string is returned for zero timestamp.
+func (t Timestamp) String() string {
+	if t.time.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.Millis(), 10)
+}
+
+// Int64 returns epoch milliseconds. It is kept for compatibility with json.Number, use Millis instead.
+func (t Timestamp) Int64() (int64, error) {
+	return strconv.ParseInt(t.String(), 10, 64)
+}
+
+// Float64 returns epoch milliseconds. It is kept for compatibility with json.Number, use Millis instead.
+func (t Timestamp) Float64() (float64, error) {
+	return strconv.ParseFloat(t.String(), 64)
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.raw != "" {
+		return []byte(t.raw), nil
+	}
+	return []byte(strconv.FormatInt(t.Millis(), 10)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*t = Timestamp{raw: string(data)}
+		return nil
+	}
+
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return errors.Wrap(err, "failed to unmarshal timestamp")
+		}
+		if value == "" {
+			*t = Timestamp{raw: string(data)}
+			return nil
+		}
+	}
+
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		*t = TimestampFromMillis(ms)
+		t.raw = string(data)
+		return nil
+	}
+	if ms, err := strconv.ParseFloat(value, 64); err == nil {
+		*t = TimestampFromMillis(int64(ms))
+		t.raw = string(data)
+		return nil
+	}
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		*t = Timestamp{time: parsed.UTC(), raw: string(data)}
+		return nil
+	}
+
+	return errors.Errorf("invalid timestamp: %s", string(data))
+}
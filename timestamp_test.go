@@ -0,0 +1,110 @@
+package zooz
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	expected := time.Date(2017, 12, 29, 12, 20, 0, 0, time.UTC)
+
+	for _, data := range []string{
+		`1514550000000`,
+		`"1514550000000"`,
+		`"2017-12-29T12:20:00Z"`,
+		`"2017-12-29T15:20:00.000+03:00"`,
+	} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(data), &ts); err != nil {
+			t.Errorf("Unmarshal error for %s: %s", data, err)
+			continue
+		}
+		if !ts.Time().Equal(expected) {
+			t.Errorf("Invalid time for %s: %s", data, ts.Time())
+		}
+		if ts.Millis() != 1514550000000 {
+			t.Errorf("Invalid millis for %s: %d", data, ts.Millis())
+		}
+
+		encoded, err := json.Marshal(ts)
+		if err != nil {
+			t.Errorf("Marshal error for %s: %s", data, err)
+		}
+		if string(encoded) != data {
+			t.Errorf("Marshaling is not lossless: %s -> %s", data, encoded)
+		}
+	}
+}
+
+func TestTimestamp_UnmarshalJSON_Empty(t *testing.T) {
+	for _, data := range []string{`null`, `""`} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(data), &ts); err != nil {
+			t.Errorf("Unmarshal error for %s: %s", data, err)
+		}
+		if !ts.IsZero() {
+			t.Errorf("Timestamp must be zero for %s: %s", data, ts.Time())
+		}
+		if encoded, err := json.Marshal(ts); err != nil || string(encoded) != data {
+			t.Errorf("Marshaling is not lossless: %s -> %s", data, encoded)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("Invalid timestamp must return error")
+	}
+}
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	encoded, err := json.Marshal(struct {
+		Created  Timestamp `json:"created"`
+		Modified Timestamp `json:"modified"`
+	}{
+		Created: NewTimestamp(time.Date(2017, 12, 29, 12, 20, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("Marshal error: %s", err)
+	}
+	if string(encoded) != `{"created":1514550000000,"modified":0}` {
+		t.Errorf("Invalid JSON: %s", encoded)
+	}
+}
+
+func TestTimestamp_Compare(t *testing.T) {
+	early := TimestampFromMillis(1514550000000)
+	late := TimestampFromMillis(1514550000001)
+
+	var fromString Timestamp
+	if err := json.Unmarshal([]byte(`"1514550000000"`), &fromString); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+
+	if !early.Before(late) || early.After(late) || !late.After(early) {
+		t.Error("Invalid Before/After")
+	}
+	if !early.Equal(fromString) {
+		t.Error("Timestamps with different representations must be equal")
+	}
+	if early.Compare(late) != -1 || late.Compare(early) != 1 || early.Compare(fromString) != 0 {
+		t.Error("Invalid Compare")
+	}
+}
+
+func TestTimestamp_NumberCompatibility(t *testing.T) {
+	var payment Payment
+	if err := json.Unmarshal([]byte(`{"created":"1514550000000"}`), &payment); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+
+	if payment.Created.String() != "1514550000000" {
+		t.Errorf("Invalid string: %s", payment.Created.String())
+	}
+	if ms, err := payment.Created.Int64(); err != nil || ms != 1514550000000 {
+		t.Errorf("Invalid Int64: %d, %v", ms, err)
+	}
+	if _, err := payment.Modified.Int64(); err == nil {
+		t.Error("Int64 of zero timestamp must return error, as json.Number does")
+	}
+}
@@ -0,0 +1,344 @@
+package zooz
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldError describes one invalid field of request params. Field is a dot-separated JSON path,
+// e.g. "order.line_items[1].quantity".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error implements error interface.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is a list of invalid fields returned by Validate methods and by Client with validation enabled.
+type ValidationErrors []FieldError
+
+// Error implements error interface.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Error())
+	}
+	return "invalid params: " + strings.Join(messages, "; ")
+}
+
+// Validator is implemented by request params which may be checked before request is sent.
+type Validator interface {
+	Validate() error
+}
+
+// OptValidation returns option which makes Client validate request params implementing Validator before every call.
+// Invalid params are not sent, and ValidationErrors is returned instead.
+func OptValidation() Option {
+	return func(c *Client) {
+		c.validate = true
+	}
+}
+
+// validateRequest validates request object if it implements Validator.
+func validateRequest(reqObj interface{}) error {
+	validator, ok := reqObj.(Validator)
+	if !ok {
+		return nil
+	}
+	if v := reflect.ValueOf(reqObj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return validator.Validate()
+}
+
+// MaxSoftDescriptorLength is a maximal length of statement soft descriptor accepted by card networks.
+const MaxSoftDescriptorLength = 22
+
+var softDescriptorRegexp = regexp.MustCompile(`^[A-Za-z0-9 .,*'&/#+\-]*$`)
+
+// validation collects field errors.
+type validation struct {
+	errs ValidationErrors
+}
+
+func (v *validation) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// nested adds errors of nested params with field prefix.
+func (v *validation) nested(prefix string, err error) {
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, fieldError := range errs {
+			v.errs = append(v.errs, FieldError{Field: prefix + "." + fieldError.Field, Message: fieldError.Message})
+		}
+	}
+}
+
+func (v *validation) currency(field, currency string) {
+	switch {
+	case currency == "":
+		v.add(field, "is required")
+	case ValidateCurrency(currency) != nil:
+		v.add(field, "%q is not ISO 4217 currency code", currency)
+	}
+}
+
+func (v *validation) nonNegative(field string, amount int64) {
+	if amount < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+func (v *validation) email(field, email string) {
+	if email == "" {
+		return
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 || strings.ContainsAny(email, " \t\r\n") {
+		v.add(field, "%q is not valid email", email)
+	}
+}
+
+func (v *validation) url(field, rawURL string) {
+	if rawURL == "" {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "%q is not absolute HTTP URL", rawURL)
+	}
+}
+
+func (v *validation) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Validate checks payment params and returns ValidationErrors if some fields are invalid.
+func (p *PaymentParams) Validate() error {
+	var v validation
+	v.nonNegative("amount", p.Amount)
+	v.currency("currency", p.Currency)
+
+	if descriptor := p.StatementSoftDescriptor; descriptor != "" {
+		if len(descriptor) > MaxSoftDescriptorLength {
+			v.add("statement_soft_descriptor", "must not be longer than %d characters", MaxSoftDescriptorLength)
+		}
+		if !softDescriptorRegexp.MatchString(descriptor) {
+			v.add("statement_soft_descriptor", "contains characters other than latin letters, digits, spaces and .,*'&/#+-")
+		}
+	}
+
+	if p.Order != nil {
+		v.nested("order", p.Order.Validate())
+		if len(p.Order.LineItems) > 0 {
+			if total := p.Order.Total(); total != p.Amount {
+				v.add("order.line_items", "total %d doesn't match payment amount %d", total, p.Amount)
+			}
+		}
+	}
+	if p.ShippingAddress != nil {
+		v.nested("shipping_address", p.ShippingAddress.Validate())
+	}
+	if p.BillingAddress != nil {
+		v.nested("billing_address", p.BillingAddress.Validate())
+	}
+
+	return v.err()
+}
+
+// Validate checks order and its line items and returns ValidationErrors if some fields are invalid.
+func (o *PaymentOrder) Validate() error {
+	var v validation
+	v.nonNegative("tax_amount", o.TaxAmount)
+	if o.TaxPercentage < 0 || o.TaxPercentage > 100 {
+		v.add("tax_percentage", "must be between 0 and 100")
+	}
+	v.nonNegative("shipping_amount", o.ShippingAmount)
+	v.nonNegative("duty_amount", o.DutyAmount)
+	v.nonNegative("discount_amount", o.DiscountAmount)
+
+	var lineTax int64
+	for i, item := range o.LineItems {
+		field := fmt.Sprintf("line_items[%d]", i)
+		if item.Quantity <= 0 {
+			v.add(field+".quantity", "must be positive")
+		}
+		v.nonNegative(field+".unit_price", item.UnitPrice)
+		v.nonNegative(field+".tax_amount", item.TaxAmount)
+		if item.DiscountAmount < 0 {
+			v.add(field+".discount_amount", "must not be negative")
+		} else if item.DiscountAmount > item.Quantity*item.UnitPrice {
+			v.add(field+".discount_amount", "must not exceed line amount %d", item.Quantity*item.UnitPrice)
+		}
+		if item.TotalAmount != 0 && item.TotalAmount != item.Total() {
+			v.add(field+".total_amount", "%d doesn't match calculated total %d", item.TotalAmount, item.Total())
+		}
+		lineTax += item.TaxAmount
+	}
+	if o.TaxAmount != 0 && o.TaxAmount < lineTax {
+		v.add("tax_amount", "%d is less than sum of line items tax %d", o.TaxAmount, lineTax)
+	}
+	return v.err()
+}
+
+// Validate checks address and returns ValidationErrors if some fields are invalid.
+func (a *Address) Validate() error {
+	var v validation
+	if a.Country != "" {
+		if err := ValidateCountry(a.Country); err != nil {
+			v.add("country", "%q is not ISO 3166-1 alpha-3 country code", a.Country)
+		}
+	}
+	v.email("email", a.Email)
+	return v.err()
+}
+
+// Validate checks customer params and returns ValidationErrors if some fields are invalid.
+func (p *CustomerParams) Validate() error {
+	var v validation
+	if p.CustomerReference == "" {
+		v.add("customer_reference", "is required")
+	}
+	v.email("email", p.Email)
+	if p.ShippingAddress != nil {
+		v.nested("shipping_address", p.ShippingAddress.Validate())
+	}
+	return v.err()
+}
+
+// Validate checks authorization params and returns ValidationErrors if some fields are invalid.
+func (p *AuthorizationParams) Validate() error {
+	return validateTransaction(p.PaymentMethod, p.MerchantSiteURL, p.ThreeDSecureAttributes, p.Installments, p.StoredCredential)
+}
+
+// Validate checks charge params and returns ValidationErrors if some fields are invalid.
+func (p *ChargeParams) Validate() error {
+	return validateTransaction(p.PaymentMethod, p.MerchantSiteURL, p.ThreeDSecureAttributes, p.Installments, p.StoredCredential)
+}
+
+func validateTransaction(paymentMethod PaymentMethodDetails, merchantSiteURL string, threeDSecure *ThreeDSecureAttributes, installments *Installments, storedCredential *StoredCredential) error {
+	var v validation
+	switch {
+	case paymentMethod.Type == "":
+		v.add("payment_method.type", "is required")
+	case paymentMethod.hasTypedSource():
+		if source, err := paymentMethod.Source(); err != nil {
+			v.add("payment_method", "%s", err)
+		} else {
+			v.nested("payment_method", source.Validate())
+		}
+	}
+	v.url("merchant_site_url", merchantSiteURL)
+	if threeDSecure != nil {
+		v.nested("three_d_secure_attributes", threeDSecure.Validate())
+		if threeDSecure.Internal != nil && merchantSiteURL == "" {
+			v.add("merchant_site_url", "is required for 3-D Secure challenge")
+		}
+	}
+	if installments != nil {
+		v.nested("installments", installments.Validate())
+	}
+	if storedCredential != nil {
+		v.nested("stored_credential", storedCredential.Validate())
+	}
+	return v.err()
+}
+
+// Validate checks installments arithmetic and returns ValidationErrors if some fields are invalid.
+func (i *Installments) Validate() error {
+	var v validation
+	if i.NumberOfInstallments < 1 {
+		v.add("number_of_installments", "must be positive")
+	}
+	v.nonNegative("first_payment_amount", i.FirstPaymentAmount)
+	v.nonNegative("remaining_payments_amount", i.RemainingPaymentsAmount)
+	if i.NumberOfInstallments == 1 && i.RemainingPaymentsAmount != 0 {
+		v.add("remaining_payments_amount", "must be zero for single installment")
+	}
+	if i.NumberOfInstallments > 1 && i.RemainingPaymentsAmount == 0 {
+		v.add("remaining_payments_amount", "is required for %d installments", i.NumberOfInstallments)
+	}
+	return v.err()
+}
+
+// ValidateAmount checks installments and their sum against payment amount: first payment plus remaining payments
+// must be equal to the amount.
+func (i *Installments) ValidateAmount(amount int64) error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+	var v validation
+	if total := i.Total(); total != amount {
+		v.add("first_payment_amount", "plus %d remaining payments of %d is %d, must be equal to payment amount %d",
+			i.NumberOfInstallments-1, i.RemainingPaymentsAmount, total, amount)
+	}
+	return v.err()
+}
+
+// Total returns sum of all installments.
+func (i *Installments) Total() int64 {
+	if i.NumberOfInstallments < 1 {
+		return 0
+	}
+	return i.FirstPaymentAmount + i.RemainingPaymentsAmount*(i.NumberOfInstallments-1)
+}
+
+// Validate checks capture params and returns ValidationErrors if some fields are invalid.
+// Nil params are valid and capture the whole authorized amount.
+func (p *CaptureParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+	var v validation
+	v.nonNegative("amount", p.Amount)
+	return v.err()
+}
+
+// Validate checks refund params and returns ValidationErrors if some fields are invalid.
+// Nil params are valid and refund the whole captured amount.
+func (p *RefundParams) Validate() error {
+	if p == nil {
+		return nil
+	}
+	var v validation
+	v.nonNegative("amount", p.Amount)
+	return v.err()
+}
+
+// ValidateCountry returns error if country is not ISO 3166-1 alpha-3 code, used by API in addresses.
+func ValidateCountry(country string) error {
+	if !countryCodes[country] {
+		return errors.Errorf("unknown country %q", country)
+	}
+	return nil
+}
+
+// countryCodes is a set of ISO 3166-1 alpha-3 country codes.
+var countryCodes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		ABW AFG AGO AIA ALA ALB AND ARE ARG ARM ASM ATA ATF ATG AUS AUT AZE BDI BEL BEN BES BFA BGD BGR BHR BHS
+		BIH BLM BLR BLZ BMU BOL BRA BRB BRN BTN BVT BWA CAF CAN CCK CHE CHL CHN CIV CMR COD COG COK COL COM CPV
+		CRI CUB CUW CXR CYM CYP CZE DEU DJI DMA DNK DOM DZA ECU EGY ERI ESH ESP EST ETH FIN FJI FLK FRA FRO FSM
+		GAB GBR GEO GGY GHA GIB GIN GLP GMB GNB GNQ GRC GRD GRL GTM GUF GUM GUY HKG HMD HND HRV HTI HUN IDN IMN
+		IND IOT IRL IRN IRQ ISL ISR ITA JAM JEY JOR JPN KAZ KEN KGZ KHM KIR KNA KOR KWT LAO LBN LBR LBY LCA LIE
+		LKA LSO LTU LUX LVA MAC MAF MAR MCO MDA MDG MDV MEX MHL MKD MLI MLT MMR MNE MNG MNP MOZ MRT MSR MTQ MUS
+		MWI MYS MYT NAM NCL NER NFK NGA NIC NIU NLD NOR NPL NRU NZL OMN PAK PAN PCN PER PHL PLW PNG POL PRI PRK
+		PRT PRY PSE PYF QAT REU ROU RUS RWA SAU SDN SEN SGP SGS SHN SJM SLB SLE SLV SMR SOM SPM SRB SSD STP SUR
+		SVK SVN SWE SWZ SXM SYC SYR TCA TCD TGO THA TJK TKL TKM TLS TON TTO TUN TUR TUV TWN TZA UGA UKR UMI URY
+		USA UZB VAT VCT VEN VGB VIR VNM VUT WLF WSM YEM ZAF ZMB ZWE`) {
+		countryCodes[code] = true
+	}
+}
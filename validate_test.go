@@ -0,0 +1,171 @@
+package zooz
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+)
+
+func validationFields(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Error must be ValidationErrors: %T %s", err, err)
+	}
+	fields := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		fields = append(fields, fieldError.Field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Validator
+		expected []string
+	}{
+		{
+			name: "valid payment",
+			params: &PaymentParams{
+				Amount:                  1100,
+				Currency:                "USD",
+				StatementSoftDescriptor: "SHOP*ORDER 42",
+				Order: &PaymentOrder{
+					TaxAmount: 100,
+					LineItems: []PaymentOrderLineItem{{Quantity: 2, UnitPrice: 250}, {Quantity: 1, UnitPrice: 500}},
+				},
+				BillingAddress: &Address{Country: "USA", Email: "john@example.com"},
+			},
+		},
+		{
+			name: "invalid payment",
+			params: &PaymentParams{
+				Amount:                  -1,
+				Currency:                "usd",
+				StatementSoftDescriptor: "Very long soft descriptor!",
+				Order: &PaymentOrder{
+					TaxPercentage: 120,
+					LineItems:     []PaymentOrderLineItem{{Quantity: 0, UnitPrice: 100}},
+				},
+				ShippingAddress: &Address{Country: "US", Email: "john"},
+			},
+			expected: []string{
+				"amount",
+				"currency",
+				"order.line_items",
+				"order.line_items[0].quantity",
+				"order.tax_percentage",
+				"shipping_address.country",
+				"shipping_address.email",
+				"statement_soft_descriptor",
+				"statement_soft_descriptor",
+			},
+		},
+		{
+			name:     "payment without currency",
+			params:   &PaymentParams{Amount: 100},
+			expected: []string{"currency"},
+		},
+		{
+			name:     "customer",
+			params:   &CustomerParams{Email: "@example.com", ShippingAddress: &Address{Country: "XYZ"}},
+			expected: []string{"customer_reference", "email", "shipping_address.country"},
+		},
+		{
+			name: "valid authorization",
+			params: &AuthorizationParams{
+				PaymentMethod:   PaymentMethodDetails{Type: "tokenized", Token: "token"},
+				MerchantSiteURL: "https://example.com/return",
+				Installments:    &Installments{NumberOfInstallments: 3, FirstPaymentAmount: 400, RemainingPaymentsAmount: 300},
+			},
+		},
+		{
+			name: "invalid authorization",
+			params: &AuthorizationParams{
+				PaymentMethod:   PaymentMethodDetails{Type: "tokenized"},
+				MerchantSiteURL: "/return",
+				Installments:    &Installments{NumberOfInstallments: 1, FirstPaymentAmount: 400, RemainingPaymentsAmount: 300},
+			},
+			expected: []string{"installments.remaining_payments_amount", "merchant_site_url", "payment_method.token"},
+		},
+		{
+			name: "invalid charge",
+			params: &ChargeParams{
+				Installments: &Installments{NumberOfInstallments: 0, FirstPaymentAmount: -1},
+			},
+			expected: []string{"installments.first_payment_amount", "installments.number_of_installments", "payment_method.type"},
+		},
+		{
+			name:     "capture",
+			params:   &CaptureParams{Amount: -5},
+			expected: []string{"amount"},
+		},
+		{
+			name:     "refund",
+			params:   &RefundParams{Amount: -5},
+			expected: []string{"amount"},
+		},
+		{
+			name:   "nil refund",
+			params: (*RefundParams)(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		if fields := validationFields(t, tt.params.Validate()); !equalFields(fields, tt.expected) {
+			t.Errorf("Invalid fields of %s are %v, expected %v", tt.name, fields, tt.expected)
+		}
+	}
+}
+
+func TestInstallments_Total(t *testing.T) {
+	i := &Installments{NumberOfInstallments: 3, FirstPaymentAmount: 400, RemainingPaymentsAmount: 300}
+	if total := i.Total(); total != 1000 {
+		t.Errorf("Total is %d", total)
+	}
+	if err := i.ValidateAmount(1000); err != nil {
+		t.Errorf("Installments must be valid: %s", err)
+	}
+	if fields := validationFields(t, i.ValidateAmount(1200)); !equalFields(fields, []string{"first_payment_amount"}) {
+		t.Errorf("Invalid fields are %v", fields)
+	}
+	if fields := validationFields(t, (&Installments{}).ValidateAmount(0)); !equalFields(fields, []string{"number_of_installments"}) {
+		t.Errorf("Invalid fields are %v", fields)
+	}
+}
+
+func TestOptValidation(t *testing.T) {
+	var calls int
+	c := New(
+		OptValidation(),
+		OptHTTPClient(&httpClientMock{do: func(r *http.Request) (*http.Response, error) {
+			calls++
+			return nil, nil
+		}}),
+	)
+
+	_, err := c.Payment().New(context.Background(), "key", &PaymentParams{Amount: 100, Currency: "XXX"})
+	if fields := validationFields(t, err); !equalFields(fields, []string{"currency"}) {
+		t.Errorf("Invalid fields are %v", fields)
+	}
+	if calls != 0 {
+		t.Errorf("Invalid params must not be sent")
+	}
+}
@@ -0,0 +1,260 @@
+package zooz
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CheckResult is a result of comparison of one value (street, postal code, CVV) by issuer.
+type CheckResult string
+
+// List of possible check results.
+const (
+	CheckResultMatch   CheckResult = "match"
+	CheckResultNoMatch CheckResult = "no_match"
+	// CheckResultUnavailable means value was not checked: not provided, not supported by issuer or system error.
+	CheckResultUnavailable CheckResult = "unavailable"
+)
+
+// AvsCode is a result code of address verification (AVS) in ProviderData.AvsCode.
+type AvsCode string
+
+// AvsCodeInfo describes AVS result code.
+type AvsCodeInfo struct {
+	Street      CheckResult
+	PostalCode  CheckResult
+	Description string
+}
+
+// AvsCodes describes AVS result codes of card networks. Unknown codes are treated as unavailable check.
+var AvsCodes = map[AvsCode]AvsCodeInfo{
+	"A": {Street: CheckResultMatch, PostalCode: CheckResultNoMatch, Description: "Street address matches, postal code does not"},
+	"B": {Street: CheckResultMatch, PostalCode: CheckResultUnavailable, Description: "Street address matches, postal code not verified"},
+	"C": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Street address and postal code not verified"},
+	"D": {Street: CheckResultMatch, PostalCode: CheckResultMatch, Description: "Street address and postal code match (international)"},
+	"E": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "AVS error"},
+	"F": {Street: CheckResultMatch, PostalCode: CheckResultMatch, Description: "Street address and postal code match (UK)"},
+	"G": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Issuer does not participate in AVS"},
+	"I": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Address not verified (international)"},
+	"M": {Street: CheckResultMatch, PostalCode: CheckResultMatch, Description: "Street address and postal code match (international)"},
+	"N": {Street: CheckResultNoMatch, PostalCode: CheckResultNoMatch, Description: "Neither street address nor postal code matches"},
+	"P": {Street: CheckResultUnavailable, PostalCode: CheckResultMatch, Description: "Postal code matches, street address not verified"},
+	"R": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Issuer system unavailable, retry"},
+	"S": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "AVS not supported"},
+	"U": {Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Address information unavailable"},
+	"W": {Street: CheckResultNoMatch, PostalCode: CheckResultMatch, Description: "9-digit postal code matches, street address does not"},
+	"X": {Street: CheckResultMatch, PostalCode: CheckResultMatch, Description: "Street address and 9-digit postal code match"},
+	"Y": {Street: CheckResultMatch, PostalCode: CheckResultMatch, Description: "Street address and 5-digit postal code match"},
+	"Z": {Street: CheckResultNoMatch, PostalCode: CheckResultMatch, Description: "5-digit postal code matches, street address does not"},
+}
+
+func (c AvsCode) info() AvsCodeInfo {
+	if info, ok := AvsCodes[c]; ok {
+		return info
+	}
+	return AvsCodeInfo{Street: CheckResultUnavailable, PostalCode: CheckResultUnavailable, Description: "Unknown AVS code"}
+}
+
+// Street returns result of street address check.
+func (c AvsCode) Street() CheckResult {
+	return c.info().Street
+}
+
+// PostalCode returns result of postal code check.
+func (c AvsCode) PostalCode() CheckResult {
+	return c.info().PostalCode
+}
+
+// Description returns human-readable meaning of the code.
+func (c AvsCode) Description() string {
+	return c.info().Description
+}
+
+// CvvCode is a result code of card verification value (CVV2/CVC2) check.
+type CvvCode string
+
+// CvvCodeInfo describes CVV result code.
+type CvvCodeInfo struct {
+	Result      CheckResult
+	Description string
+}
+
+// CvvCodes describes CVV result codes of card networks. Unknown codes are treated as unavailable check.
+var CvvCodes = map[CvvCode]CvvCodeInfo{
+	"M": {Result: CheckResultMatch, Description: "CVV matches"},
+	"N": {Result: CheckResultNoMatch, Description: "CVV does not match"},
+	"P": {Result: CheckResultUnavailable, Description: "CVV not processed"},
+	"S": {Result: CheckResultUnavailable, Description: "CVV should be on the card, but merchant indicated it is not"},
+	"U": {Result: CheckResultUnavailable, Description: "Issuer does not support CVV check"},
+}
+
+func (c CvvCode) info() CvvCodeInfo {
+	if info, ok := CvvCodes[c]; ok {
+		return info
+	}
+	return CvvCodeInfo{Result: CheckResultUnavailable, Description: "Unknown CVV code"}
+}
+
+// Result returns result of CVV check.
+func (c CvvCode) Result() CheckResult {
+	return c.info().Result
+}
+
+// Description returns human-readable meaning of the code.
+func (c CvvCode) Description() string {
+	return c.info().Description
+}
+
+// cvvInformationKeys are names of AdditionalInformation fields with CVV result used by providers which don't
+// return ProviderData.CvvVerificationCode.
+var cvvInformationKeys = []string{"cvv_result", "cvv_response", "cvv_code", "cvc_result"}
+
+// AVS returns typed AVS result code of transaction.
+func (p ProviderData) AVS() AvsCode {
+	return AvsCode(strings.ToUpper(strings.TrimSpace(p.AvsCode)))
+}
+
+// CVV returns typed CVV result code of transaction.
+func (p ProviderData) CVV() CvvCode {
+	code := p.CvvVerificationCode
+	for _, key := range cvvInformationKeys {
+		if code != "" {
+			break
+		}
+		code = p.AdditionalInformation[key]
+	}
+	return CvvCode(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// VerificationFailure is a reason of rejection of authorization by VerificationPolicy.
+type VerificationFailure string
+
+// List of possible verification failures.
+const (
+	VerificationFailureAvsNoMatch         VerificationFailure = "avs_no_match"
+	VerificationFailureStreetMismatch     VerificationFailure = "street_mismatch"
+	VerificationFailurePostalCodeMismatch VerificationFailure = "postal_code_mismatch"
+	VerificationFailureAvsUnavailable     VerificationFailure = "avs_unavailable"
+	VerificationFailureCvvMismatch        VerificationFailure = "cvv_mismatch"
+	VerificationFailureCvvUnavailable     VerificationFailure = "cvv_unavailable"
+)
+
+// VerificationPolicy is a set of rules rejecting approved authorizations by AVS and CVV results.
+type VerificationPolicy struct {
+	// RejectAvsNoMatch rejects if neither street nor postal code matches.
+	RejectAvsNoMatch         bool
+	RejectStreetMismatch     bool
+	RejectPostalCodeMismatch bool
+	// RejectAvsUnavailable rejects if neither street nor postal code was checked.
+	RejectAvsUnavailable bool
+	RejectCvvMismatch    bool
+	RejectCvvUnavailable bool
+}
+
+// DefaultVerificationPolicy returns policy which rejects CVV mismatch and mismatch of both street and postal code.
+func DefaultVerificationPolicy() VerificationPolicy {
+	return VerificationPolicy{
+		RejectAvsNoMatch:  true,
+		RejectCvvMismatch: true,
+	}
+}
+
+// Evaluate returns failed rules for given AVS and CVV codes. Empty result means authorization is accepted.
+func (p VerificationPolicy) Evaluate(avs AvsCode, cvv CvvCode) []VerificationFailure {
+	var failures []VerificationFailure
+	street, postalCode := avs.Street(), avs.PostalCode()
+	if p.RejectAvsNoMatch && street == CheckResultNoMatch && postalCode == CheckResultNoMatch {
+		failures = append(failures, VerificationFailureAvsNoMatch)
+	}
+	if p.RejectStreetMismatch && street == CheckResultNoMatch {
+		failures = append(failures, VerificationFailureStreetMismatch)
+	}
+	if p.RejectPostalCodeMismatch && postalCode == CheckResultNoMatch {
+		failures = append(failures, VerificationFailurePostalCodeMismatch)
+	}
+	if p.RejectAvsUnavailable && street == CheckResultUnavailable && postalCode == CheckResultUnavailable {
+		failures = append(failures, VerificationFailureAvsUnavailable)
+	}
+	if p.RejectCvvMismatch && cvv.Result() == CheckResultNoMatch {
+		failures = append(failures, VerificationFailureCvvMismatch)
+	}
+	if p.RejectCvvUnavailable && cvv.Result() == CheckResultUnavailable {
+		failures = append(failures, VerificationFailureCvvUnavailable)
+	}
+	return failures
+}
+
+// VerificationDecision is a decision of VerificationGuard about approved authorization.
+type VerificationDecision struct {
+	PaymentID       string
+	AuthorizationID string
+	Avs             AvsCode
+	Cvv             CvvCode
+	// Failures are failed rules of the policy. Authorization is rejected if there are any.
+	Failures []VerificationFailure
+	// Void is set if rejected authorization was voided.
+	Void *Void
+	// VoidErr is set if void of rejected authorization failed or its result is not approved.
+	VoidErr error
+}
+
+// IsRejected reports whether authorization failed policy rules.
+func (d VerificationDecision) IsRejected() bool {
+	return len(d.Failures) > 0
+}
+
+// VerificationGuard checks approved authorizations against VerificationPolicy and voids rejected ones.
+type VerificationGuard struct {
+	Policy VerificationPolicy
+	// Voids is used to void rejected authorizations if AutoVoid is set.
+	Voids    VoidAPI
+	AutoVoid bool
+	// OnDecision is called for every checked authorization, if set.
+	OnDecision func(decision VerificationDecision)
+}
+
+// NewVerificationGuard creates guard which voids rejected authorizations.
+func NewVerificationGuard(policy VerificationPolicy, voids VoidAPI, onDecision func(decision VerificationDecision)) *VerificationGuard {
+	return &VerificationGuard{
+		Policy:     policy,
+		Voids:      voids,
+		AutoVoid:   voids != nil,
+		OnDecision: onDecision,
+	}
+}
+
+// Check evaluates AVS and CVV results of authorization and voids it if it is rejected. Authorizations which are
+// not approved are not checked, zero decision is returned for them. Error is returned if void failed.
+// Void is created with idempotency key idempotencyKey+"/void", so idempotency key of authorization may be passed.
+func (g *VerificationGuard) Check(ctx context.Context, idempotencyKey string, paymentID string, authorization *Authorization) (VerificationDecision, error) {
+	if authorization == nil {
+		return VerificationDecision{}, errors.Errorf("authorization of payment %s is nil", paymentID)
+	}
+	if !authorization.Result.IsApproved() {
+		return VerificationDecision{}, nil
+	}
+
+	decision := VerificationDecision{
+		PaymentID:       paymentID,
+		AuthorizationID: authorization.ID,
+		Avs:             authorization.ProviderData.AVS(),
+		Cvv:             authorization.ProviderData.CVV(),
+	}
+	decision.Failures = g.Policy.Evaluate(decision.Avs, decision.Cvv)
+
+	if decision.IsRejected() && g.AutoVoid && g.Voids != nil {
+		decision.Void, decision.VoidErr = g.Voids.New(ctx, idempotencyKey+"/void", paymentID)
+		if decision.VoidErr == nil && !decision.Void.Result.IsApproved() {
+			decision.VoidErr = errors.Errorf("void %s failed: %s %s", decision.Void.ID, decision.Void.Result.Status, decision.Void.Result.Category)
+		}
+	}
+	if g.OnDecision != nil {
+		g.OnDecision(decision)
+	}
+	if decision.VoidErr != nil {
+		return decision, errors.Wrapf(decision.VoidErr, "failed to void authorization %s of payment %s", authorization.ID, paymentID)
+	}
+	return decision, nil
+}
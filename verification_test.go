@@ -0,0 +1,127 @@
+package zooz
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProviderData_AVS_CVV(t *testing.T) {
+	tests := []struct {
+		providerData ProviderData
+		street       CheckResult
+		postalCode   CheckResult
+		cvv          CheckResult
+	}{
+		{providerData: ProviderData{AvsCode: "Y", CvvVerificationCode: "M"}, street: CheckResultMatch, postalCode: CheckResultMatch, cvv: CheckResultMatch},
+		{providerData: ProviderData{AvsCode: " z ", CvvVerificationCode: "n"}, street: CheckResultNoMatch, postalCode: CheckResultMatch, cvv: CheckResultNoMatch},
+		{providerData: ProviderData{AvsCode: "A", AdditionalInformation: map[string]string{"cvv_result": "P"}}, street: CheckResultMatch, postalCode: CheckResultNoMatch, cvv: CheckResultUnavailable},
+		{providerData: ProviderData{AvsCode: "N"}, street: CheckResultNoMatch, postalCode: CheckResultNoMatch, cvv: CheckResultUnavailable},
+		{providerData: ProviderData{AvsCode: "?"}, street: CheckResultUnavailable, postalCode: CheckResultUnavailable, cvv: CheckResultUnavailable},
+	}
+
+	for _, tt := range tests {
+		avs, cvv := tt.providerData.AVS(), tt.providerData.CVV()
+		if avs.Street() != tt.street || avs.PostalCode() != tt.postalCode || cvv.Result() != tt.cvv {
+			t.Errorf("Results of %+v are %s/%s/%s", tt.providerData, avs.Street(), avs.PostalCode(), cvv.Result())
+		}
+		if avs.Description() == "" || cvv.Description() == "" {
+			t.Errorf("Description of %+v is empty", tt.providerData)
+		}
+	}
+}
+
+func TestVerificationPolicy_Evaluate(t *testing.T) {
+	strict := VerificationPolicy{
+		RejectStreetMismatch:     true,
+		RejectPostalCodeMismatch: true,
+		RejectAvsUnavailable:     true,
+		RejectCvvMismatch:        true,
+		RejectCvvUnavailable:     true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   VerificationPolicy
+		avs      AvsCode
+		cvv      CvvCode
+		expected []VerificationFailure
+	}{
+		{name: "default match", policy: DefaultVerificationPolicy(), avs: "Y", cvv: "M"},
+		{name: "default partial match", policy: DefaultVerificationPolicy(), avs: "Z", cvv: "U"},
+		{name: "default no match", policy: DefaultVerificationPolicy(), avs: "N", cvv: "N", expected: []VerificationFailure{VerificationFailureAvsNoMatch, VerificationFailureCvvMismatch}},
+		{name: "strict street", policy: strict, avs: "Z", cvv: "M", expected: []VerificationFailure{VerificationFailureStreetMismatch}},
+		{name: "strict postal code", policy: strict, avs: "A", cvv: "M", expected: []VerificationFailure{VerificationFailurePostalCodeMismatch}},
+		{name: "strict unavailable", policy: strict, avs: "U", cvv: "", expected: []VerificationFailure{VerificationFailureAvsUnavailable, VerificationFailureCvvUnavailable}},
+	}
+
+	for _, tt := range tests {
+		if failures := tt.policy.Evaluate(tt.avs, tt.cvv); !reflect.DeepEqual(failures, tt.expected) {
+			t.Errorf("Failures of %s are %v, expected %v", tt.name, failures, tt.expected)
+		}
+	}
+}
+
+func TestVerificationGuard_Check(t *testing.T) {
+	m := NewMock()
+	m.On("Void.New", "key/void", "payment").Return(&Void{ID: "void", Result: Result{Status: ResultStatusSucceed}}, nil).Once()
+
+	var decisions []VerificationDecision
+	guard := NewVerificationGuard(DefaultVerificationPolicy(), m.Void(), func(decision VerificationDecision) {
+		decisions = append(decisions, decision)
+	})
+
+	approved := Result{Status: ResultStatusSucceed}
+	decision, err := guard.Check(context.Background(), "key", "payment", &Authorization{
+		ID:           "authorization",
+		Result:       approved,
+		ProviderData: ProviderData{AvsCode: "Y", CvvVerificationCode: "N"},
+	})
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if !decision.IsRejected() || decision.Void == nil || decision.Void.ID != "void" || decision.AuthorizationID != "authorization" {
+		t.Errorf("Decision is not as expected: %+v", decision)
+	}
+
+	decision, err = guard.Check(context.Background(), "key", "payment", &Authorization{Result: approved, ProviderData: ProviderData{AvsCode: "Y", CvvVerificationCode: "M"}})
+	if err != nil || decision.IsRejected() || decision.Void != nil {
+		t.Errorf("Decision is not as expected: %+v, %v", decision, err)
+	}
+
+	if _, err := guard.Check(context.Background(), "key", "payment", &Authorization{Result: Result{Status: ResultStatusFailed}}); err != nil {
+		t.Errorf("Error must be nil: %s", err)
+	}
+
+	if _, err := guard.Check(context.Background(), "key", "payment", nil); err == nil {
+		t.Error("Error expected for nil authorization")
+	}
+
+	if len(decisions) != 2 {
+		t.Errorf("Decisions are not as expected: %+v", decisions)
+	}
+	m.AssertExpectations(t)
+
+	m = NewMock()
+	m.On("Void.New", "key/void", "payment").Return(nil, errors.New("void failed")).Once()
+	guard = NewVerificationGuard(DefaultVerificationPolicy(), m.Void(), nil)
+	decision, err = guard.Check(context.Background(), "key", "payment", &Authorization{Result: approved, ProviderData: ProviderData{AvsCode: "N"}})
+	if err == nil || decision.VoidErr == nil {
+		t.Errorf("Error expected when void failed: %+v", decision)
+	}
+
+	m = NewMock()
+	m.On("Void.New", "key/void", "payment").Return(&Void{ID: "void", Result: Result{Status: ResultStatusFailed, Category: ResultCategoryProviderError}}, nil).Once()
+	guard = NewVerificationGuard(DefaultVerificationPolicy(), m.Void(), nil)
+	decision, err = guard.Check(context.Background(), "key", "payment", &Authorization{Result: approved, ProviderData: ProviderData{AvsCode: "N"}})
+	if err == nil || decision.VoidErr == nil || decision.Void == nil {
+		t.Errorf("Error expected when void is declined: %+v", decision)
+	}
+
+	guard.AutoVoid = false
+	decision, err = guard.Check(context.Background(), "key", "payment", &Authorization{Result: approved, ProviderData: ProviderData{AvsCode: "N"}})
+	if err != nil || !decision.IsRejected() || decision.Void != nil {
+		t.Errorf("Decision is not as expected without auto void: %+v, %v", decision, err)
+	}
+}
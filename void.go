@@ -14,12 +14,23 @@ type VoidClient struct {
 
 // Void is an entity model.
 type Void struct {
+	RawFields
+
 	ID           string       `json:"id"`
 	Result       Result       `json:"result"`
-	Created      json.Number  `json:"created"`
+	Created      Timestamp    `json:"created"`
 	ProviderData ProviderData `json:"provider_data"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (v *Void) UnmarshalJSON(data []byte) error {
+	type model Void
+	if err := json.Unmarshal(data, (*model)(v)); err != nil {
+		return err
+	}
+	return v.RawFields.decode(data, v)
+}
+
 // New create new Void entity.
 func (c *VoidClient) New(ctx context.Context, idempotencyKey string, paymentID string) (*Void, error) {
 	void := &Void{}
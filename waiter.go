@@ -0,0 +1,245 @@
+package zooz
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WaitEvent describes status change observed by Waiter.
+type WaitEvent struct {
+	Attempt int
+	At      time.Time
+	Payment *Payment
+	// PaymentStatus is a status of the payment.
+	PaymentStatus PaymentStatus
+	// TransactionStatus is a result status of awaited authorization, charge or refund.
+	// It is empty while waiting for payment status or if transaction is not found yet.
+	TransactionStatus ResultStatus
+}
+
+// UnexpectedStatusError is returned by Waiter.WaitForPayment when payment leaves Pending status,
+// but the new status is not one of awaited statuses.
+type UnexpectedStatusError struct {
+	PaymentID string
+	Status    PaymentStatus
+	Expected  []PaymentStatus
+}
+
+// Error implements error interface.
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("payment %s got status %q, expected one of %q", e.PaymentID, e.Status, e.Expected)
+}
+
+// Waiter polls payments and their transactions until they leave Pending status.
+// Payment is requested with expansions, so payment and transaction status are checked with one call per attempt.
+type Waiter struct {
+	payments        PaymentAPI
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	multiplier      float64
+	jitter          float64
+	onChange        func(WaitEvent)
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// WaitOption is a callback for redefine waiter parameters.
+type WaitOption func(*Waiter)
+
+// NewWaiter creates waiter with given options. By default it waits 1 second before the second attempt and doubles
+// interval up to 30 seconds with 20% jitter.
+func NewWaiter(payments PaymentAPI, options ...WaitOption) *Waiter {
+	w := &Waiter{
+		payments:        payments,
+		initialInterval: time.Second,
+		maxInterval:     30 * time.Second,
+		multiplier:      2,
+		jitter:          0.2,
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// minWaitInterval is the minimal interval between attempts, so misconfigured backoff doesn't flood API.
+const minWaitInterval = 10 * time.Millisecond
+
+// WaitOptBackoff returns option with given backoff: first interval, max interval and interval multiplier.
+// Non-positive initial interval and multiplier keep defaults, non-positive max interval means no limit.
+// Intervals are never shorter than 10ms.
+func WaitOptBackoff(initial, max time.Duration, multiplier float64) WaitOption {
+	return func(w *Waiter) {
+		if initial > 0 {
+			w.initialInterval = initial
+		}
+		w.maxInterval = max
+		if multiplier > 0 {
+			w.multiplier = multiplier
+		}
+	}
+}
+
+// WaitOptJitter returns option with given jitter: each interval is randomly changed by up to jitter*interval.
+func WaitOptJitter(jitter float64, seed int64) WaitOption {
+	return func(w *Waiter) {
+		w.jitter = jitter
+		w.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WaitOptOnChange returns option with callback, which is called for the first observed status
+// and every status change.
+func WaitOptOnChange(onChange func(WaitEvent)) WaitOption {
+	return func(w *Waiter) {
+		w.onChange = onChange
+	}
+}
+
+// WaitForPayment polls payment until it leaves Pending status. If statuses are given and payment gets any other
+// status, *UnexpectedStatusError is returned along with the payment.
+func (w *Waiter) WaitForPayment(ctx context.Context, paymentID string, statuses ...PaymentStatus) (*Payment, error) {
+	var payment *Payment
+	err := w.poll(ctx, paymentID, nil, func(p *Payment) (ResultStatus, bool) {
+		payment = p
+		return "", p.Status != PaymentStatusPending
+	})
+	if err != nil {
+		return payment, err
+	}
+
+	if len(statuses) == 0 {
+		return payment, nil
+	}
+	for _, status := range statuses {
+		if payment.Status == status {
+			return payment, nil
+		}
+	}
+	return payment, &UnexpectedStatusError{PaymentID: paymentID, Status: payment.Status, Expected: statuses}
+}
+
+// WaitForAuthorization polls authorization until its result leaves Pending status.
+func (w *Waiter) WaitForAuthorization(ctx context.Context, paymentID string, authorizationID string) (*Authorization, error) {
+	var authorization *Authorization
+	err := w.poll(ctx, paymentID, []PaymentExpand{PaymentExpandAuthorizations}, func(p *Payment) (ResultStatus, bool) {
+		if p.RelatedResources == nil {
+			return "", false
+		}
+		for i := range p.RelatedResources.Authorizations {
+			if a := &p.RelatedResources.Authorizations[i]; a.ID == authorizationID {
+				authorization = a
+				return a.Result.Status, !a.Result.IsPending()
+			}
+		}
+		return "", false
+	})
+	return authorization, err
+}
+
+// WaitForCharge polls charge until its result leaves Pending status.
+func (w *Waiter) WaitForCharge(ctx context.Context, paymentID string, chargeID string) (*Charge, error) {
+	var charge *Charge
+	err := w.poll(ctx, paymentID, []PaymentExpand{PaymentExpandAll}, func(p *Payment) (ResultStatus, bool) {
+		if p.RelatedResources == nil {
+			return "", false
+		}
+		for i := range p.RelatedResources.Charges {
+			if c := &p.RelatedResources.Charges[i]; c.ID == chargeID {
+				charge = c
+				return c.Result.Status, !c.Result.IsPending()
+			}
+		}
+		return "", false
+	})
+	return charge, err
+}
+
+// WaitForRefund polls refund until its result leaves Pending status.
+func (w *Waiter) WaitForRefund(ctx context.Context, paymentID string, refundID string) (*Refund, error) {
+	var refund *Refund
+	err := w.poll(ctx, paymentID, []PaymentExpand{PaymentExpandRefunds}, func(p *Payment) (ResultStatus, bool) {
+		if p.RelatedResources == nil {
+			return "", false
+		}
+		for i := range p.RelatedResources.Refunds {
+			if r := &p.RelatedResources.Refunds[i]; r.ID == refundID {
+				refund = r
+				return r.Result.Status, !r.Result.IsPending()
+			}
+		}
+		return "", false
+	})
+	return refund, err
+}
+
+// poll gets payment until check returns done. Check returns status of awaited transaction as well.
+func (w *Waiter) poll(ctx context.Context, paymentID string, expands []PaymentExpand, check func(*Payment) (ResultStatus, bool)) error {
+	var last *WaitEvent
+	interval := w.initialInterval
+
+	for attempt := 1; ; attempt++ {
+		payment, err := w.payments.Get(ctx, paymentID, expands...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get payment %s", paymentID)
+		}
+
+		transactionStatus, done := check(payment)
+
+		if last == nil || last.PaymentStatus != payment.Status || last.TransactionStatus != transactionStatus {
+			last = &WaitEvent{
+				Attempt:           attempt,
+				At:                time.Now(),
+				Payment:           payment,
+				PaymentStatus:     payment.Status,
+				TransactionStatus: transactionStatus,
+			}
+			if w.onChange != nil {
+				w.onChange(*last)
+			}
+		}
+
+		if done {
+			return nil
+		}
+
+		delay := w.withJitter(interval)
+		if delay < minWaitInterval {
+			delay = minWaitInterval
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * w.multiplier)
+		if w.maxInterval > 0 && interval > w.maxInterval {
+			interval = w.maxInterval
+		}
+		if interval < minWaitInterval {
+			interval = minWaitInterval
+		}
+	}
+}
+
+func (w *Waiter) withJitter(interval time.Duration) time.Duration {
+	if w.jitter <= 0 {
+		return interval
+	}
+	w.mu.Lock()
+	factor := 1 + w.jitter*(2*w.rand.Float64()-1)
+	w.mu.Unlock()
+	return time.Duration(float64(interval) * factor)
+}
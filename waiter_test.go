@@ -0,0 +1,91 @@
+package zooz
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaiter_WaitForPayment(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.Get").Return(&Payment{ID: "id", Status: PaymentStatusPending}, nil).Times(2)
+	mock.On("Payment.Get").Return(&Payment{ID: "id", Status: PaymentStatusAuthorized}, nil).Once()
+
+	var events []WaitEvent
+	w := NewWaiter(
+		mock.Payment(),
+		WaitOptBackoff(time.Millisecond, 2*time.Millisecond, 2),
+		WaitOptJitter(0.5, 1),
+		WaitOptOnChange(func(e WaitEvent) { events = append(events, e) }),
+	)
+
+	payment, err := w.WaitForPayment(context.Background(), "id", PaymentStatusAuthorized)
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if payment.Status != PaymentStatusAuthorized {
+		t.Errorf("Invalid status: %s", payment.Status)
+	}
+	if len(events) != 2 || events[0].PaymentStatus != PaymentStatusPending || events[1].PaymentStatus != PaymentStatusAuthorized || events[1].Attempt != 3 {
+		t.Errorf("Invalid events: %+v", events)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestWaiter_WaitForPayment_UnexpectedStatus(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.Get").Return(&Payment{ID: "id", Status: PaymentStatusInitialized}, nil).Once()
+
+	_, err := NewWaiter(mock.Payment()).WaitForPayment(context.Background(), "id", PaymentStatusAuthorized)
+	if _, ok := err.(*UnexpectedStatusError); !ok {
+		t.Errorf("Invalid error: %v", err)
+	}
+}
+
+func TestWaiter_WaitForAuthorization(t *testing.T) {
+	pending := &Payment{ID: "id", Status: PaymentStatusPending, RelatedResources: &PaymentRelatedResources{
+		Authorizations: []Authorization{{ID: "authorization_id", Result: Result{Status: ResultStatusPending}}},
+	}}
+	authorized := &Payment{ID: "id", Status: PaymentStatusAuthorized, RelatedResources: &PaymentRelatedResources{
+		Authorizations: []Authorization{{ID: "authorization_id", Result: Result{Status: ResultStatusSucceed}}},
+	}}
+
+	mock := NewMock()
+	mock.On("Payment.Get", "id", []PaymentExpand{PaymentExpandAuthorizations}).Return(pending, nil).Once()
+	mock.On("Payment.Get", "id", []PaymentExpand{PaymentExpandAuthorizations}).Return(authorized, nil).Once()
+
+	w := NewWaiter(mock.Payment(), WaitOptBackoff(time.Millisecond, time.Millisecond, 1))
+
+	authorization, err := w.WaitForAuthorization(context.Background(), "id", "authorization_id")
+	if err != nil {
+		t.Fatalf("Error must be nil: %s", err)
+	}
+	if !authorization.Result.IsApproved() {
+		t.Errorf("Authorization is not as expected: %+v", authorization)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestWaiter_RespectsContext(t *testing.T) {
+	mock := NewMock()
+	mock.On("Payment.Get").Return(&Payment{ID: "id", Status: PaymentStatusPending}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	refund, err := NewWaiter(mock.Payment(), WaitOptBackoff(time.Millisecond, 5*time.Millisecond, 2)).
+		WaitForRefund(ctx, "id", "refund_id")
+	if err != context.DeadlineExceeded {
+		t.Errorf("Invalid error: %v", err)
+	}
+	if refund != nil {
+		t.Errorf("Refund must be nil: %+v", refund)
+	}
+}
+
+func TestWaitOptBackoff_NonPositive(t *testing.T) {
+	w := NewWaiter(NewMock().Payment(), WaitOptBackoff(0, 0, -1))
+	if w.initialInterval != time.Second || w.multiplier != 2 || w.maxInterval != 0 {
+		t.Errorf("Non-positive backoff must keep defaults: %v %v %v", w.initialInterval, w.maxInterval, w.multiplier)
+	}
+}